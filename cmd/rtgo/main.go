@@ -2,19 +2,29 @@ package main
 
 import (
 	"flag"
+	"log"
+	"math"
 
 	"github.com/davherrmann/rtgo"
+	"github.com/davherrmann/rtgo/loader/hdr"
 	"github.com/davherrmann/rtgo/raytracing"
 )
 
 type Config struct {
 	Port string
+
+	Environment          string
+	EnvironmentIntensity float64
+	EnvironmentRotation  float64
 }
 
 func parseConfig() Config {
 	config := Config{}
 
 	flag.StringVar(&config.Port, "port", "8080", "listen port for server")
+	flag.StringVar(&config.Environment, "environment", "", "equirectangular .hdr or .pfm image lighting the scene")
+	flag.Float64Var(&config.EnvironmentIntensity, "environment-intensity", 1, "multiplier for the environment radiance")
+	flag.Float64Var(&config.EnvironmentRotation, "environment-rotation", 0, "rotation of the environment around the up axis in degrees")
 	flag.Parse()
 
 	return config
@@ -30,9 +40,19 @@ func main() {
 		{R: 0.30, G: 0.89, B: 1},
 	}
 	camera := rtgo.GenerateCamera(0, 1, 400, 300)
-	world := rtgo.GenerateWorld(randomColorPalette)
+	scene := rtgo.GenerateWorld(randomColorPalette)
+
+	if config.Environment != "" {
+		image, err := hdr.Load(config.Environment)
+		if err != nil {
+			log.Fatal(err)
+		}
+		env := raytracing.NewEnvironmentMap(image, config.EnvironmentRotation*math.Pi/180, config.EnvironmentIntensity)
+		scene.Background = env.Background
+		scene.Lights = append(scene.Lights, env)
+	}
 
 	// http server
-	handler := rtgo.NewServer(camera, world)
+	handler := rtgo.NewServer(camera, scene)
 	handler.ListenAndServe(config.Port)
 }
@@ -0,0 +1,206 @@
+package gltf
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"strings"
+)
+
+func (d *decoder) loadBuffers() error {
+	d.buffers = make([][]byte, len(d.doc.Buffers))
+
+	for i, buf := range d.doc.Buffers {
+		var data []byte
+		var err error
+
+		switch {
+		case buf.URI == "":
+			// the binary chunk of a GLB file
+			if i != 0 || d.binaryChunk == nil {
+				return fmt.Errorf("buffer %d: missing uri", i)
+			}
+			data = d.binaryChunk
+		case strings.HasPrefix(buf.URI, "data:"):
+			data, err = decodeDataURI(buf.URI)
+		default:
+			data, err = d.readExternal(buf.URI)
+		}
+		if err != nil {
+			return fmt.Errorf("buffer %d: %w", i, err)
+		}
+
+		if len(data) < buf.ByteLength {
+			return fmt.Errorf("buffer %d: expected %d bytes, got %d", i, buf.ByteLength, len(data))
+		}
+		d.buffers[i] = data
+	}
+
+	return nil
+}
+
+func decodeDataURI(uri string) ([]byte, error) {
+	comma := strings.IndexByte(uri, ',')
+	if comma < 0 || !strings.HasSuffix(uri[:comma], ";base64") {
+		return nil, errors.New("only base64 data uris are supported")
+	}
+	return base64.StdEncoding.DecodeString(uri[comma+1:])
+}
+
+func (d *decoder) readExternal(uri string) ([]byte, error) {
+	if d.openFile == nil {
+		return nil, fmt.Errorf("cannot open external file %q", uri)
+	}
+
+	name, err := url.PathUnescape(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := d.openFile(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
+
+var componentCounts = map[string]int{
+	"SCALAR": 1,
+	"VEC2":   2,
+	"VEC3":   3,
+	"VEC4":   4,
+	"MAT2":   4,
+	"MAT3":   9,
+	"MAT4":   16,
+}
+
+var componentSizes = map[int]int{
+	componentByte:          1,
+	componentUnsignedByte:  1,
+	componentShort:         2,
+	componentUnsignedShort: 2,
+	componentUnsignedInt:   4,
+	componentFloat:         4,
+}
+
+// maxAccessorCount bounds the elements of an accessor, so that malformed
+// files can't request huge allocations
+const maxAccessorCount = 1 << 26
+
+// readAccessor returns the accessor's elements as float64 values, count
+// times the number of components per element
+func (d *decoder) readAccessor(index int) ([]float64, int, error) {
+	if index < 0 || index >= len(d.doc.Accessors) {
+		return nil, 0, fmt.Errorf("accessor %d out of range", index)
+	}
+	acc := d.doc.Accessors[index]
+
+	components, ok := componentCounts[acc.Type]
+	if !ok {
+		return nil, 0, fmt.Errorf("accessor %d: unknown type %q", index, acc.Type)
+	}
+	size, ok := componentSizes[acc.ComponentType]
+	if !ok {
+		return nil, 0, fmt.Errorf("accessor %d: unknown component type %d", index, acc.ComponentType)
+	}
+	if acc.Sparse != nil {
+		return nil, 0, fmt.Errorf("accessor %d: sparse accessors are not supported", index)
+	}
+
+	if acc.Count < 0 || acc.Count > maxAccessorCount {
+		return nil, 0, fmt.Errorf("accessor %d: invalid count %d", index, acc.Count)
+	}
+	if acc.ByteOffset < 0 {
+		return nil, 0, fmt.Errorf("accessor %d: invalid byte offset %d", index, acc.ByteOffset)
+	}
+
+	// accessors without buffer view are all zeros
+	if acc.BufferView == nil {
+		return make([]float64, acc.Count*components), components, nil
+	}
+
+	if *acc.BufferView < 0 || *acc.BufferView >= len(d.doc.BufferViews) {
+		return nil, 0, fmt.Errorf("accessor %d: buffer view %d out of range", index, *acc.BufferView)
+	}
+	view := d.doc.BufferViews[*acc.BufferView]
+	if view.Buffer < 0 || view.Buffer >= len(d.buffers) {
+		return nil, 0, fmt.Errorf("buffer view %d: buffer %d out of range", *acc.BufferView, view.Buffer)
+	}
+	data := d.buffers[view.Buffer]
+	// bounding the offsets by the buffer keeps the sums below from
+	// overflowing
+	if view.ByteOffset < 0 || view.ByteLength < 0 || view.ByteOffset > len(data) || view.ByteLength > len(data) {
+		return nil, 0, fmt.Errorf("buffer view %d: invalid byte range", *acc.BufferView)
+	}
+	if acc.ByteOffset > view.ByteLength {
+		return nil, 0, fmt.Errorf("accessor %d: byte offset %d exceeds buffer view", index, acc.ByteOffset)
+	}
+
+	elementSize := size * components
+	stride := view.ByteStride
+	if stride == 0 {
+		stride = elementSize
+	}
+	if stride < elementSize || stride > len(data) {
+		return nil, 0, fmt.Errorf("buffer view %d: invalid byte stride %d", *acc.BufferView, view.ByteStride)
+	}
+
+	start := view.ByteOffset + acc.ByteOffset
+	if acc.Count > 0 {
+		end := start + (acc.Count-1)*stride + elementSize
+		if end > view.ByteOffset+view.ByteLength || end > len(data) {
+			return nil, 0, fmt.Errorf("accessor %d: data exceeds buffer view", index)
+		}
+	}
+
+	values := make([]float64, acc.Count*components)
+	for i := 0; i < acc.Count; i++ {
+		for c := 0; c < components; c++ {
+			offset := start + i*stride + c*size
+			values[i*components+c] = readComponent(data[offset:], acc.ComponentType, acc.Normalized)
+		}
+	}
+
+	return values, components, nil
+}
+
+func readComponent(data []byte, componentType int, normalized bool) float64 {
+	le := binary.LittleEndian
+
+	switch componentType {
+	case componentFloat:
+		return float64(math.Float32frombits(le.Uint32(data)))
+	case componentByte:
+		v := float64(int8(data[0]))
+		if normalized {
+			return math.Max(v/127, -1)
+		}
+		return v
+	case componentUnsignedByte:
+		v := float64(data[0])
+		if normalized {
+			return v / 255
+		}
+		return v
+	case componentShort:
+		v := float64(int16(le.Uint16(data)))
+		if normalized {
+			return math.Max(v/32767, -1)
+		}
+		return v
+	case componentUnsignedShort:
+		v := float64(le.Uint16(data))
+		if normalized {
+			return v / 65535
+		}
+		return v
+	default:
+		return float64(le.Uint32(data))
+	}
+}
@@ -0,0 +1,117 @@
+package gltf
+
+// JSON structure of a glTF 2.0 asset, limited to the parts used for rendering
+
+type document struct {
+	Asset struct {
+		Version string `json:"version"`
+	} `json:"asset"`
+
+	Scene       *int         `json:"scene"`
+	Scenes      []scene      `json:"scenes"`
+	Nodes       []node       `json:"nodes"`
+	Meshes      []mesh       `json:"meshes"`
+	Materials   []material   `json:"materials"`
+	Cameras     []camera     `json:"cameras"`
+	Accessors   []accessor   `json:"accessors"`
+	BufferViews []bufferView `json:"bufferViews"`
+	Buffers     []buffer     `json:"buffers"`
+}
+
+type scene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type node struct {
+	Name        string    `json:"name"`
+	Children    []int     `json:"children"`
+	Mesh        *int      `json:"mesh"`
+	Camera      *int      `json:"camera"`
+	Matrix      []float64 `json:"matrix"`
+	Translation []float64 `json:"translation"`
+	Rotation    []float64 `json:"rotation"`
+	Scale       []float64 `json:"scale"`
+}
+
+type mesh struct {
+	Name       string      `json:"name"`
+	Primitives []primitive `json:"primitives"`
+}
+
+type primitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices"`
+	Material   *int           `json:"material"`
+	Mode       *int           `json:"mode"`
+}
+
+const (
+	modeTriangles     = 4
+	modeTriangleStrip = 5
+	modeTriangleFan   = 6
+)
+
+type material struct {
+	Name                 string `json:"name"`
+	PBRMetallicRoughness *struct {
+		BaseColorFactor []float64 `json:"baseColorFactor"`
+		MetallicFactor  *float64  `json:"metallicFactor"`
+		RoughnessFactor *float64  `json:"roughnessFactor"`
+	} `json:"pbrMetallicRoughness"`
+	Extensions struct {
+		Transmission *struct {
+			TransmissionFactor float64 `json:"transmissionFactor"`
+		} `json:"KHR_materials_transmission"`
+		IOR *struct {
+			IOR *float64 `json:"ior"`
+		} `json:"KHR_materials_ior"`
+		Clearcoat *struct {
+			ClearcoatFactor          float64 `json:"clearcoatFactor"`
+			ClearcoatRoughnessFactor float64 `json:"clearcoatRoughnessFactor"`
+		} `json:"KHR_materials_clearcoat"`
+	} `json:"extensions"`
+}
+
+type camera struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Perspective *struct {
+		AspectRatio float64 `json:"aspectRatio"`
+		YFov        float64 `json:"yfov"`
+	} `json:"perspective"`
+	Orthographic *struct {
+		XMag float64 `json:"xmag"`
+		YMag float64 `json:"ymag"`
+	} `json:"orthographic"`
+}
+
+type accessor struct {
+	BufferView    *int      `json:"bufferView"`
+	ByteOffset    int       `json:"byteOffset"`
+	ComponentType int       `json:"componentType"`
+	Normalized    bool      `json:"normalized"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Sparse        *struct{} `json:"sparse"`
+}
+
+const (
+	componentByte          = 5120
+	componentUnsignedByte  = 5121
+	componentShort         = 5122
+	componentUnsignedShort = 5123
+	componentUnsignedInt   = 5125
+	componentFloat         = 5126
+)
+
+type bufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride"`
+}
+
+type buffer struct {
+	ByteLength int    `json:"byteLength"`
+	URI        string `json:"uri"`
+}
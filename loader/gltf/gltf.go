@@ -0,0 +1,480 @@
+package gltf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+type Vec = raytracing.Vec
+
+// Scene is an imported glTF scene
+type Scene struct {
+	World   raytracing.Hittable
+	Cameras []raytracing.Camera
+}
+
+// Load reads a .gltf or .glb file, resolving external buffers relative
+// to its directory
+func Load(path string) (*Scene, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	dir := filepath.Dir(path)
+	openFile := func(name string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(dir, name))
+	}
+
+	return Decode(file, openFile)
+}
+
+// Decode reads glTF JSON or binary GLB data from r, using openFile to
+// resolve external buffers
+func Decode(r io.Reader, openFile func(name string) (io.ReadCloser, error)) (*Scene, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := decoder{openFile: openFile}
+
+	jsonChunk := data
+	if bytes.HasPrefix(data, glbMagic) {
+		jsonChunk, d.binaryChunk, err = splitGLB(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := json.Unmarshal(jsonChunk, &d.doc); err != nil {
+		return nil, fmt.Errorf("invalid glTF json: %w", err)
+	}
+	if len(d.doc.Asset.Version) < 1 || d.doc.Asset.Version[0] != '2' {
+		return nil, fmt.Errorf("unsupported glTF version %q", d.doc.Asset.Version)
+	}
+
+	if err := d.loadBuffers(); err != nil {
+		return nil, err
+	}
+
+	return d.buildScene()
+}
+
+var glbMagic = []byte("glTF")
+
+const (
+	glbChunkJSON = 0x4e4f534a
+	glbChunkBIN  = 0x004e4942
+)
+
+func splitGLB(data []byte) (jsonChunk, binaryChunk []byte, err error) {
+	le := binary.LittleEndian
+
+	if len(data) < 12 {
+		return nil, nil, errors.New("glb: truncated header")
+	}
+	if version := le.Uint32(data[4:]); version != 2 {
+		return nil, nil, fmt.Errorf("glb: unsupported version %d", version)
+	}
+	length := int(le.Uint32(data[8:]))
+	if length > len(data) {
+		return nil, nil, errors.New("glb: truncated file")
+	}
+
+	for offset := 12; offset+8 <= length; {
+		chunkLength := int(le.Uint32(data[offset:]))
+		chunkType := le.Uint32(data[offset+4:])
+		start := offset + 8
+		end := start + chunkLength
+		if end > length {
+			return nil, nil, errors.New("glb: truncated chunk")
+		}
+
+		switch chunkType {
+		case glbChunkJSON:
+			jsonChunk = data[start:end]
+		case glbChunkBIN:
+			if binaryChunk == nil {
+				binaryChunk = data[start:end]
+			}
+		}
+
+		offset = end
+	}
+
+	if jsonChunk == nil {
+		return nil, nil, errors.New("glb: missing json chunk")
+	}
+	return jsonChunk, binaryChunk, nil
+}
+
+type decoder struct {
+	doc         document
+	openFile    func(name string) (io.ReadCloser, error)
+	binaryChunk []byte
+	buffers     [][]byte
+
+	materials []raytracing.Material
+	meshes    map[int][]raytracing.Hittable
+	objects   []raytracing.Hittable
+	cameras   []raytracing.Camera
+}
+
+type primitiveData struct {
+	positions []Vec
+	normals   []Vec
+	texCoords [][2]float64
+	indices   []int
+	material  raytracing.Material
+}
+
+var defaultMaterial = raytracing.Lambertian(raytracing.Color{R: 1, G: 1, B: 1})
+
+func (d *decoder) buildScene() (*Scene, error) {
+	d.meshes = make(map[int][]raytracing.Hittable)
+	d.materials = make([]raytracing.Material, len(d.doc.Materials))
+	for i, m := range d.doc.Materials {
+		d.materials[i] = convertMaterial(m)
+	}
+
+	var roots []int
+	switch {
+	case d.doc.Scene != nil && *d.doc.Scene >= 0 && *d.doc.Scene < len(d.doc.Scenes):
+		roots = d.doc.Scenes[*d.doc.Scene].Nodes
+	case d.doc.Scene != nil:
+		return nil, fmt.Errorf("scene %d out of range", *d.doc.Scene)
+	case len(d.doc.Scenes) > 0:
+		roots = d.doc.Scenes[0].Nodes
+	}
+
+	for _, root := range roots {
+		if err := d.visitNode(root, raytracing.Identity, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Scene{
+		World:   raytracing.NewBVH(d.objects),
+		Cameras: d.cameras,
+	}, nil
+}
+
+// the node hierarchy is a tree, the depth limit guards against cycles
+const maxNodeDepth = 256
+
+func (d *decoder) visitNode(index int, parent raytracing.Matrix, depth int) error {
+	if index < 0 || index >= len(d.doc.Nodes) {
+		return fmt.Errorf("node %d out of range", index)
+	}
+	if depth > maxNodeDepth {
+		return fmt.Errorf("node %d: hierarchy too deep or cyclic", index)
+	}
+
+	n := d.doc.Nodes[index]
+	transform := parent.Multiply(localTransform(n))
+
+	if n.Mesh != nil {
+		if err := d.addMesh(*n.Mesh, transform); err != nil {
+			return fmt.Errorf("node %d: %w", index, err)
+		}
+	}
+
+	if n.Camera != nil {
+		if err := d.addCamera(*n.Camera, transform); err != nil {
+			return fmt.Errorf("node %d: %w", index, err)
+		}
+	}
+
+	for _, child := range n.Children {
+		if err := d.visitNode(child, transform, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func localTransform(n node) raytracing.Matrix {
+	if len(n.Matrix) == 16 {
+		return fromColumnMajor(n.Matrix)
+	}
+
+	t := [3]float64{0, 0, 0}
+	r := [4]float64{0, 0, 0, 1}
+	s := [3]float64{1, 1, 1}
+	if len(n.Translation) == 3 {
+		copy(t[:], n.Translation)
+	}
+	if len(n.Rotation) == 4 {
+		copy(r[:], n.Rotation)
+	}
+	if len(n.Scale) == 3 {
+		copy(s[:], n.Scale)
+	}
+
+	return fromTRS(t, r, s)
+}
+
+func (d *decoder) addCamera(index int, transform raytracing.Matrix) error {
+	if index < 0 || index >= len(d.doc.Cameras) {
+		return fmt.Errorf("camera %d out of range", index)
+	}
+
+	var projection raytracing.Projection
+	switch c := d.doc.Cameras[index]; {
+	case c.Type == "perspective" && c.Perspective != nil:
+		projection = raytracing.Perspective{VerticalFOV: c.Perspective.YFov}
+	case c.Type == "orthographic" && c.Orthographic != nil:
+		// the width follows from the aspect ratio of the image
+		projection = raytracing.Orthographic{Height: 2 * c.Orthographic.YMag}
+	default:
+		return fmt.Errorf("camera %d: invalid type %q", index, c.Type)
+	}
+
+	// glTF cameras look down -Z with +Y up
+	from := transform.TransformPoint(Vec{})
+	forward := transform.TransformVector(Vec{Z: -1}).Normalized()
+	up := transform.TransformVector(Vec{Y: 1}).Normalized()
+
+	d.cameras = append(d.cameras, raytracing.Camera{
+		From:       from,
+		LookAt:     from.Add(forward),
+		Up:         up,
+		Projection: projection,
+	})
+
+	return nil
+}
+
+func (d *decoder) addMesh(index int, transform raytracing.Matrix) error {
+	meshes, err := d.loadMesh(index)
+	if err != nil {
+		return err
+	}
+
+	if transform == raytracing.Identity {
+		d.objects = append(d.objects, meshes...)
+		return nil
+	}
+
+	for _, mesh := range meshes {
+		transformed, err := raytracing.NewTransformed(mesh, transform)
+		if err != nil {
+			return fmt.Errorf("mesh %d: %w", index, err)
+		}
+		d.objects = append(d.objects, transformed)
+	}
+
+	return nil
+}
+
+// loadMesh builds the primitives of a mesh once, nodes referencing the same
+// mesh share its vertex data
+func (d *decoder) loadMesh(index int) ([]raytracing.Hittable, error) {
+	if meshes, ok := d.meshes[index]; ok {
+		return meshes, nil
+	}
+	if index < 0 || index >= len(d.doc.Meshes) {
+		return nil, fmt.Errorf("mesh %d out of range", index)
+	}
+
+	var meshes []raytracing.Hittable
+	for i, p := range d.doc.Meshes[index].Primitives {
+		data, ok, err := d.loadPrimitive(p)
+		if err != nil {
+			return nil, fmt.Errorf("mesh %d primitive %d: %w", index, i, err)
+		}
+		if ok {
+			mesh, err := raytracing.NewMesh(data.positions, data.normals, data.texCoords, data.indices, data.material)
+			if err != nil {
+				return nil, fmt.Errorf("mesh %d primitive %d: %w", index, i, err)
+			}
+			meshes = append(meshes, mesh)
+		}
+	}
+
+	d.meshes[index] = meshes
+	return meshes, nil
+}
+
+func (d *decoder) loadPrimitive(p primitive) (primitiveData, bool, error) {
+	mode := modeTriangles
+	if p.Mode != nil {
+		mode = *p.Mode
+	}
+	if mode != modeTriangles && mode != modeTriangleStrip && mode != modeTriangleFan {
+		// points and lines have no surface
+		return primitiveData{}, false, nil
+	}
+
+	positionAccessor, ok := p.Attributes["POSITION"]
+	if !ok {
+		return primitiveData{}, false, errors.New("missing POSITION attribute")
+	}
+	positions, err := d.readVectors(positionAccessor)
+	if err != nil {
+		return primitiveData{}, false, err
+	}
+	if len(positions) == 0 {
+		return primitiveData{}, false, errors.New("POSITION has no vertices")
+	}
+
+	var normals []Vec
+	if normalAccessor, ok := p.Attributes["NORMAL"]; ok {
+		normals, err = d.readVectors(normalAccessor)
+		if err != nil {
+			return primitiveData{}, false, err
+		}
+		if len(normals) != len(positions) {
+			return primitiveData{}, false, errors.New("NORMAL and POSITION counts differ")
+		}
+	}
+
+	var texCoords [][2]float64
+	if texCoordAccessor, ok := p.Attributes["TEXCOORD_0"]; ok {
+		values, components, err := d.readAccessor(texCoordAccessor)
+		if err != nil {
+			return primitiveData{}, false, err
+		}
+		if components != 2 || len(values)/2 != len(positions) {
+			return primitiveData{}, false, errors.New("TEXCOORD_0 must be VEC2 with one entry per position")
+		}
+		// glTF has its texture origin in the top left corner
+		texCoords = make([][2]float64, len(positions))
+		for i := range texCoords {
+			texCoords[i] = [2]float64{values[i*2], 1 - values[i*2+1]}
+		}
+	}
+
+	var vertexIndices []int
+	if p.Indices != nil {
+		values, _, err := d.readAccessor(*p.Indices)
+		if err != nil {
+			return primitiveData{}, false, err
+		}
+		vertexIndices = make([]int, len(values))
+		for i, value := range values {
+			vertexIndices[i] = int(value)
+			if vertexIndices[i] >= len(positions) {
+				return primitiveData{}, false, fmt.Errorf("index %d out of range", vertexIndices[i])
+			}
+		}
+	} else {
+		vertexIndices = make([]int, len(positions))
+		for i := range vertexIndices {
+			vertexIndices[i] = i
+		}
+	}
+
+	material := defaultMaterial
+	if p.Material != nil {
+		if *p.Material < 0 || *p.Material >= len(d.materials) {
+			return primitiveData{}, false, fmt.Errorf("material %d out of range", *p.Material)
+		}
+		material = d.materials[*p.Material]
+	}
+
+	indices := triangulate(vertexIndices, mode)
+	if len(indices) == 0 {
+		return primitiveData{}, false, errors.New("primitive has no triangles")
+	}
+
+	return primitiveData{
+		positions: positions,
+		normals:   normals,
+		texCoords: texCoords,
+		indices:   indices,
+		material:  material,
+	}, true, nil
+}
+
+func triangulate(indices []int, mode int) []int {
+	switch mode {
+	case modeTriangleStrip:
+		var triangles []int
+		for i := 0; i+2 < len(indices); i++ {
+			if i%2 == 0 {
+				triangles = append(triangles, indices[i], indices[i+1], indices[i+2])
+			} else {
+				triangles = append(triangles, indices[i+1], indices[i], indices[i+2])
+			}
+		}
+		return triangles
+	case modeTriangleFan:
+		var triangles []int
+		for i := 1; i+1 < len(indices); i++ {
+			triangles = append(triangles, indices[0], indices[i], indices[i+1])
+		}
+		return triangles
+	default:
+		return indices[:len(indices)/3*3]
+	}
+}
+
+func (d *decoder) readVectors(accessor int) ([]Vec, error) {
+	values, components, err := d.readAccessor(accessor)
+	if err != nil {
+		return nil, err
+	}
+	if components != 3 {
+		return nil, fmt.Errorf("accessor %d: expected VEC3", accessor)
+	}
+
+	vectors := make([]Vec, len(values)/3)
+	for i := range vectors {
+		vectors[i] = Vec{X: values[i*3], Y: values[i*3+1], Z: values[i*3+2]}
+	}
+	return vectors, nil
+}
+
+// convertMaterial maps metallic-roughness parameters onto the principled
+// material, transmissive materials become glass
+func convertMaterial(m material) raytracing.Material {
+	baseColor := raytracing.Color{R: 1, G: 1, B: 1}
+	metallic := 1.0
+	roughness := 1.0
+
+	if pbr := m.PBRMetallicRoughness; pbr != nil {
+		if len(pbr.BaseColorFactor) >= 3 {
+			baseColor = raytracing.Color{R: pbr.BaseColorFactor[0], G: pbr.BaseColorFactor[1], B: pbr.BaseColorFactor[2]}
+		}
+		if pbr.MetallicFactor != nil {
+			metallic = *pbr.MetallicFactor
+		}
+		if pbr.RoughnessFactor != nil {
+			roughness = *pbr.RoughnessFactor
+		}
+	}
+
+	ior := 1.5
+	if m.Extensions.IOR != nil && m.Extensions.IOR.IOR != nil {
+		ior = *m.Extensions.IOR.IOR
+	}
+
+	if t := m.Extensions.Transmission; t != nil && t.TransmissionFactor > 0 {
+		return raytracing.Dielectric(ior)
+	}
+
+	options := raytracing.PrincipledOptions{
+		BaseColor: baseColor,
+		Metallic:  metallic,
+		Roughness: roughness,
+		IOR:       ior,
+	}
+	if c := m.Extensions.Clearcoat; c != nil {
+		options.Clearcoat = c.ClearcoatFactor
+		options.ClearcoatRoughness = c.ClearcoatRoughnessFactor
+	}
+
+	return raytracing.Principled(options)
+}
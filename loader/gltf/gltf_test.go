@@ -0,0 +1,151 @@
+package gltf_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/davherrmann/rtgo/loader/gltf"
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+type Vec = raytracing.Vec
+
+// triangleBuffer holds three float positions followed by three uint16 indices
+func triangleBuffer() []byte {
+	buf := &bytes.Buffer{}
+	for _, v := range []float32{-1, -1, 0, 1, -1, 0, 0, 1, 0} {
+		binary.Write(buf, binary.LittleEndian, v)
+	}
+	for _, i := range []uint16{0, 1, 2} {
+		binary.Write(buf, binary.LittleEndian, i)
+	}
+	return buf.Bytes()
+}
+
+// document places the triangle twice (moved along Z) and a camera at z=5
+func document(uri string) string {
+	return fmt.Sprintf(`{
+		"asset": {"version": "2.0"},
+		"scene": 0,
+		"scenes": [{"nodes": [0, 1, 2]}],
+		"nodes": [
+			{"mesh": 0},
+			{"mesh": 0, "translation": [0, 0, -2], "scale": [2, 2, 2]},
+			{"camera": 0, "translation": [0, 0, 5]}
+		],
+		"cameras": [{"type": "perspective", "perspective": {"yfov": 1.0, "znear": 0.1}}],
+		"meshes": [{"primitives": [{"attributes": {"POSITION": 0}, "indices": 1, "material": 0}]}],
+		"materials": [{"pbrMetallicRoughness": {"baseColorFactor": [1, 0, 0, 1], "metallicFactor": 0}}],
+		"accessors": [
+			{"bufferView": 0, "componentType": 5126, "count": 3, "type": "VEC3"},
+			{"bufferView": 0, "byteOffset": 36, "componentType": 5123, "count": 3, "type": "SCALAR"}
+		],
+		"bufferViews": [{"buffer": 0, "byteLength": 42}],
+		"buffers": [{"byteLength": 42%s}]
+	}`, uri)
+}
+
+func packGLB(json string, bin []byte) []byte {
+	pad := func(data []byte, with byte) []byte {
+		for len(data)%4 != 0 {
+			data = append(data, with)
+		}
+		return data
+	}
+	jsonChunk := pad([]byte(json), ' ')
+	binChunk := pad(append([]byte{}, bin...), 0)
+
+	buf := &bytes.Buffer{}
+	le := binary.LittleEndian
+	buf.WriteString("glTF")
+	binary.Write(buf, le, uint32(2))
+	binary.Write(buf, le, uint32(12+8+len(jsonChunk)+8+len(binChunk)))
+	binary.Write(buf, le, uint32(len(jsonChunk)))
+	binary.Write(buf, le, uint32(0x4e4f534a))
+	buf.Write(jsonChunk)
+	binary.Write(buf, le, uint32(len(binChunk)))
+	binary.Write(buf, le, uint32(0x004e4942))
+	buf.Write(binChunk)
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	embedded := document(`, "uri": "data:application/octet-stream;base64,` + base64.StdEncoding.EncodeToString(triangleBuffer()) + `"`)
+	glb := packGLB(document(""), triangleBuffer())
+
+	for name, data := range map[string][]byte{"gltf": []byte(embedded), "glb": glb} {
+		scene, err := gltf.Decode(bytes.NewReader(data), nil)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		// the untransformed instance is hit first
+		hit := scene.World.Hit(raytracing.Ray{Origin: Vec{Z: 5}, Direction: Vec{Z: -1}}, 0.001, 10000)
+		if hit == nil || math.Abs(hit.T-5) > 1e-6 {
+			t.Fatalf("%s: expected hit at t=5, got %#v", name, hit)
+		}
+
+		// only the scaled instance reaches y=1.5
+		hit = scene.World.Hit(raytracing.Ray{Origin: Vec{Y: 1.5, Z: 5}, Direction: Vec{Z: -1}}, 0.001, 10000)
+		if hit == nil || math.Abs(hit.T-7) > 1e-6 {
+			t.Fatalf("%s: expected hit at t=7, got %#v", name, hit)
+		}
+
+		if len(scene.Cameras) != 1 {
+			t.Fatalf("%s: expected 1 camera, got %d", name, len(scene.Cameras))
+		}
+		camera := scene.Cameras[0]
+		if camera.From != (Vec{Z: 5}) || camera.LookAt != (Vec{Z: 4}) || camera.Up != (Vec{Y: 1}) {
+			t.Errorf("%s: unexpected camera %#v", name, camera)
+		}
+		if camera.Projection != (raytracing.Perspective{VerticalFOV: 1}) {
+			t.Errorf("%s: unexpected projection %#v", name, camera.Projection)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []string{
+		`{"asset": {"version": "1.0"}}`,
+		`{"asset": {"version": "2.0"}, "scene": 1, "scenes": [{"nodes": []}]}`,
+		`{"asset": {"version": "2.0"}, "scenes": [{"nodes": [3]}]}`,
+		`{"asset": {"version": "2.0"}, "buffers": [{"byteLength": 4, "uri": "missing.bin"}]}`,
+		document(`, "uri": "data:application/octet-stream;base64,AAAA"`),
+	}
+
+	for _, test := range tests {
+		if _, err := gltf.Decode(bytes.NewReader([]byte(test)), nil); err == nil {
+			t.Errorf("expected error for %s", test)
+		}
+	}
+}
+
+func TestDecodeMalformedAccessors(t *testing.T) {
+	replacements := map[string][2]string{
+		"negative count":        {`"count": 3, "type": "VEC3"`, `"count": -1, "type": "VEC3"`},
+		"huge count":            {`"count": 3, "type": "VEC3"`, `"count": 1000000000000, "type": "VEC3"`},
+		"count exceeds view":    {`"count": 3, "type": "VEC3"`, `"count": 4, "type": "VEC3"`},
+		"negative offset":       {`"byteOffset": 36`, `"byteOffset": -8`},
+		"negative view offset":  {`{"buffer": 0, "byteLength": 42}`, `{"buffer": 0, "byteOffset": -8, "byteLength": 42}`},
+		"negative stride":       {`{"buffer": 0, "byteLength": 42}`, `{"buffer": 0, "byteLength": 42, "byteStride": -4}`},
+		"stride below element":  {`{"buffer": 0, "byteLength": 42}`, `{"buffer": 0, "byteLength": 42, "byteStride": 2}`},
+		"view exceeds buffer":   {`{"buffer": 0, "byteLength": 42}`, `{"buffer": 0, "byteOffset": 40, "byteLength": 42}`},
+		"offset exceeds buffer": {`"byteOffset": 36`, `"byteOffset": 9223372036854775807`},
+		"no positions":          {`"count": 3, "type": "VEC3"`, `"count": 0, "type": "VEC3"`},
+		"no indices":            {`"count": 3, "type": "SCALAR"`, `"count": 0, "type": "SCALAR"`},
+		"partial triangle":      {`"count": 3, "type": "SCALAR"`, `"count": 2, "type": "SCALAR"`},
+		"singular transform":    {`"scale": [2, 2, 2]`, `"scale": [2, 0, 2]`},
+	}
+
+	for name, replacement := range replacements {
+		json := strings.Replace(document(""), replacement[0], replacement[1], 1)
+		if _, err := gltf.Decode(bytes.NewReader(packGLB(json, triangleBuffer())), nil); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package gltf
+
+import (
+	"math"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+// fromColumnMajor converts a glTF node matrix
+func fromColumnMajor(values []float64) raytracing.Matrix {
+	var m raytracing.Matrix
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			m[row][col] = values[col*4+row]
+		}
+	}
+	return m
+}
+
+// fromTRS builds translation * rotation * scale, the rotation given as
+// a quaternion (x, y, z, w)
+func fromTRS(t [3]float64, r [4]float64, s [3]float64) raytracing.Matrix {
+	x, y, z, w := normalizeQuaternion(r)
+
+	rotation := raytracing.Matrix{
+		{1 - 2*(y*y+z*z), 2 * (x*y - z*w), 2 * (x*z + y*w), 0},
+		{2 * (x*y + z*w), 1 - 2*(x*x+z*z), 2 * (y*z - x*w), 0},
+		{2 * (x*z - y*w), 2 * (y*z + x*w), 1 - 2*(x*x+y*y), 0},
+		{0, 0, 0, 1},
+	}
+
+	return raytracing.Translate(Vec{X: t[0], Y: t[1], Z: t[2]}).
+		Multiply(rotation).
+		Multiply(raytracing.Scale(Vec{X: s[0], Y: s[1], Z: s[2]}))
+}
+
+func normalizeQuaternion(q [4]float64) (x, y, z, w float64) {
+	length := math.Sqrt(q[0]*q[0] + q[1]*q[1] + q[2]*q[2] + q[3]*q[3])
+	if length == 0 {
+		return 0, 0, 0, 1
+	}
+	return q[0] / length, q[1] / length, q[2] / length, q[3] / length
+}
@@ -0,0 +1,289 @@
+// Package hdr decodes high dynamic range images in the Radiance (.hdr) and
+// Portable Float Map (.pfm) formats.
+package hdr
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+// Load reads a Radiance or PFM file, detected by its magic bytes
+func Load(path string) (*raytracing.HDRImage, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Decode(file)
+}
+
+// Decode reads a Radiance or PFM image from r
+func Decode(r io.Reader) (*raytracing.HDRImage, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil {
+		return nil, fmt.Errorf("hdr: %w", err)
+	}
+
+	switch string(magic) {
+	case "#?":
+		return DecodeRadiance(br)
+	case "PF", "Pf":
+		return DecodePFM(br)
+	}
+	return nil, errors.New("hdr: unknown image format")
+}
+
+// DecodeRadiance reads an RGBE image with flat or run length encoded
+// scanlines
+func DecodeRadiance(r io.Reader) (*raytracing.HDRImage, error) {
+	br := bufio.NewReader(r)
+
+	line, err := readLine(br)
+	if err != nil {
+		return nil, err
+	}
+	if line != "#?RADIANCE" && line != "#?RGBE" {
+		return nil, fmt.Errorf("hdr: invalid signature %q", line)
+	}
+
+	// header lines up to an empty one
+	for {
+		line, err := readLine(br)
+		if err != nil {
+			return nil, err
+		}
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "FORMAT=") && line != "FORMAT=32-bit_rle_rgbe" {
+			return nil, fmt.Errorf("hdr: unsupported format %q", strings.TrimPrefix(line, "FORMAT="))
+		}
+	}
+
+	line, err = readLine(br)
+	if err != nil {
+		return nil, err
+	}
+	var width, height int
+	if _, err := fmt.Sscanf(line, "-Y %d +X %d", &height, &width); err != nil {
+		return nil, fmt.Errorf("hdr: unsupported resolution %q", line)
+	}
+	if err := checkSize(width, height); err != nil {
+		return nil, fmt.Errorf("hdr: %w", err)
+	}
+
+	img := &raytracing.HDRImage{Width: width, Height: height, Pixels: make([]raytracing.Color, width*height)}
+	scanline := make([][4]byte, width)
+	for y := 0; y < height; y++ {
+		if err := readScanline(br, scanline); err != nil {
+			return nil, fmt.Errorf("hdr: scanline %d: %w", y, err)
+		}
+		for x, rgbe := range scanline {
+			img.Pixels[y*width+x] = fromRGBE(rgbe)
+		}
+	}
+
+	return img, nil
+}
+
+// maxDimension and maxPixels bound the size of images, so that malformed
+// headers can't request huge allocations
+const (
+	maxDimension = 1 << 16
+	maxPixels    = 1 << 26
+)
+
+func checkSize(width, height int) error {
+	if width <= 0 || height <= 0 || width > maxDimension || height > maxDimension {
+		return fmt.Errorf("invalid size %dx%d", width, height)
+	}
+	// dividing avoids overflowing the product
+	if width > maxPixels/height {
+		return fmt.Errorf("image of %dx%d pixels is too large", width, height)
+	}
+	return nil
+}
+
+func readLine(br *bufio.Reader) (string, error) {
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("hdr: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func readScanline(br *bufio.Reader, scanline [][4]byte) error {
+	var first [4]byte
+	if _, err := io.ReadFull(br, first[:]); err != nil {
+		return err
+	}
+
+	width := len(scanline)
+	if width < 8 || width > 0x7fff || first[0] != 2 || first[1] != 2 || first[2]&0x80 != 0 {
+		scanline[0] = first
+		return readFlat(br, scanline)
+	}
+	if int(first[2])<<8|int(first[3]) != width {
+		return errors.New("scanline width mismatch")
+	}
+
+	// run length encoded, one channel after the other
+	for channel := 0; channel < 4; channel++ {
+		for x := 0; x < width; {
+			count, err := br.ReadByte()
+			if err != nil {
+				return err
+			}
+
+			if count > 128 {
+				n := int(count) - 128
+				value, err := br.ReadByte()
+				if err != nil {
+					return err
+				}
+				if x+n > width {
+					return errors.New("run exceeds scanline")
+				}
+				for ; n > 0; n-- {
+					scanline[x][channel] = value
+					x++
+				}
+				continue
+			}
+
+			n := int(count)
+			if n == 0 || x+n > width {
+				return errors.New("invalid run length")
+			}
+			for ; n > 0; n-- {
+				value, err := br.ReadByte()
+				if err != nil {
+					return err
+				}
+				scanline[x][channel] = value
+				x++
+			}
+		}
+	}
+
+	return nil
+}
+
+// readFlat reads uncompressed pixels, including the old run length encoding
+// which repeats the previous pixel, starting with scanline[0] already read
+func readFlat(br *bufio.Reader, scanline [][4]byte) error {
+	shift := 0
+	for x := 0; x < len(scanline); {
+		var pixel [4]byte
+		if x == 0 {
+			pixel = scanline[0]
+		} else if _, err := io.ReadFull(br, pixel[:]); err != nil {
+			return err
+		}
+
+		if pixel[0] == 1 && pixel[1] == 1 && pixel[2] == 1 {
+			if x == 0 {
+				return errors.New("run without previous pixel")
+			}
+			n := int(pixel[3]) << shift
+			if x+n > len(scanline) {
+				return errors.New("run exceeds scanline")
+			}
+			for ; n > 0; n-- {
+				scanline[x] = scanline[x-1]
+				x++
+			}
+			shift += 8
+			continue
+		}
+
+		scanline[x] = pixel
+		x++
+		shift = 0
+	}
+	return nil
+}
+
+func fromRGBE(rgbe [4]byte) raytracing.Color {
+	if rgbe[3] == 0 {
+		return raytracing.Black
+	}
+	f := math.Ldexp(1, int(rgbe[3])-(128+8))
+	return raytracing.Color{
+		R: (float64(rgbe[0]) + 0.5) * f,
+		G: (float64(rgbe[1]) + 0.5) * f,
+		B: (float64(rgbe[2]) + 0.5) * f,
+	}
+}
+
+// DecodePFM reads a color (PF) or grayscale (Pf) float map
+func DecodePFM(r io.Reader) (*raytracing.HDRImage, error) {
+	br := bufio.NewReader(r)
+
+	var kind string
+	var width, height int
+	var scale float64
+	if _, err := fmt.Fscan(br, &kind, &width, &height, &scale); err != nil {
+		return nil, fmt.Errorf("pfm: invalid header: %w", err)
+	}
+	// exactly one whitespace character separates header and data
+	if _, err := br.ReadByte(); err != nil {
+		return nil, fmt.Errorf("pfm: %w", err)
+	}
+
+	channels := 3
+	switch kind {
+	case "PF":
+	case "Pf":
+		channels = 1
+	default:
+		return nil, fmt.Errorf("pfm: invalid signature %q", kind)
+	}
+	if err := checkSize(width, height); err != nil {
+		return nil, fmt.Errorf("pfm: %w", err)
+	}
+	if scale == 0 || math.IsNaN(scale) {
+		return nil, fmt.Errorf("pfm: invalid scale %v", scale)
+	}
+
+	data := make([]byte, width*height*channels*4)
+	if _, err := io.ReadFull(br, data); err != nil {
+		return nil, fmt.Errorf("pfm: %w", err)
+	}
+
+	// a negative scale marks little endian data
+	var order binary.ByteOrder = binary.BigEndian
+	if scale < 0 {
+		order = binary.LittleEndian
+	}
+	float := func(i int) float64 {
+		return float64(math.Float32frombits(order.Uint32(data[i*4:])))
+	}
+
+	img := &raytracing.HDRImage{Width: width, Height: height, Pixels: make([]raytracing.Color, width*height)}
+	for y := 0; y < height; y++ {
+		// rows are stored bottom to top
+		row := height - 1 - y
+		for x := 0; x < width; x++ {
+			i := (row*width + x) * channels
+			if channels == 1 {
+				v := float(i)
+				img.Pixels[y*width+x] = raytracing.Color{R: v, G: v, B: v}
+			} else {
+				img.Pixels[y*width+x] = raytracing.Color{R: float(i), G: float(i + 1), B: float(i + 2)}
+			}
+		}
+	}
+
+	return img, nil
+}
@@ -0,0 +1,99 @@
+package hdr_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/davherrmann/rtgo/loader/hdr"
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func requireColor(t *testing.T, got, want raytracing.Color) {
+	t.Helper()
+	if math.Abs(got.R-want.R) > 1e-6 || math.Abs(got.G-want.G) > 1e-6 || math.Abs(got.B-want.B) > 1e-6 {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestDecodeRadianceRLE(t *testing.T) {
+	var data bytes.Buffer
+	data.WriteString("#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y 1 +X 8\n")
+	data.Write([]byte{2, 2, 0, 8})
+	// red: run of 8, green: 8 literals, blue: run of 8, exponent: run of 8
+	data.Write([]byte{128 + 8, 128})
+	data.Write([]byte{8, 0, 16, 32, 48, 64, 80, 96, 112})
+	data.Write([]byte{128 + 8, 0})
+	data.Write([]byte{128 + 8, 129})
+
+	img, err := hdr.Decode(&data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 8 || img.Height != 1 {
+		t.Fatalf("expected 8x1 image, got %dx%d", img.Width, img.Height)
+	}
+
+	// exponent 129 scales the mantissa by 2/256
+	requireColor(t, img.At(0, 0), raytracing.Color{R: 128.5 / 128, G: 0.5 / 128, B: 0.5 / 128})
+	requireColor(t, img.At(7, 0), raytracing.Color{R: 128.5 / 128, G: 112.5 / 128, B: 0.5 / 128})
+}
+
+func TestDecodeRadianceFlat(t *testing.T) {
+	var data bytes.Buffer
+	data.WriteString("#?RGBE\n\n-Y 2 +X 2\n")
+	data.Write([]byte{64, 64, 64, 128, 1, 1, 1, 1})
+	data.Write([]byte{0, 0, 0, 0, 128, 0, 0, 129})
+
+	img, err := hdr.Decode(&data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gray := raytracing.Color{R: 64.5 / 256, G: 64.5 / 256, B: 64.5 / 256}
+	requireColor(t, img.At(0, 0), gray)
+	requireColor(t, img.At(1, 0), gray)
+	requireColor(t, img.At(0, 1), raytracing.Black)
+	requireColor(t, img.At(1, 1), raytracing.Color{R: 128.5 / 128, G: 0.5 / 128, B: 0.5 / 128})
+}
+
+func TestDecodePFM(t *testing.T) {
+	var data bytes.Buffer
+	data.WriteString("PF\n1 2\n-1.0\n")
+	// bottom row first
+	for _, v := range []float32{1, 2, 3, 4, 5, 6} {
+		binary.Write(&data, binary.LittleEndian, v)
+	}
+
+	img, err := hdr.Decode(&data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireColor(t, img.At(0, 0), raytracing.Color{R: 4, G: 5, B: 6})
+	requireColor(t, img.At(0, 1), raytracing.Color{R: 1, G: 2, B: 3})
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	inputs := []string{
+		"P6\n1 1\n255\n",
+		"#?RADIANCE\nFORMAT=32-bit_rle_xyze\n\n-Y 1 +X 1\n",
+		"#?RADIANCE\n\n+Y 1 +X 1\n",
+		"#?RADIANCE\n\n-Y 1 +X 8\n\x02\x02\x00\x08\x00",
+		"PF\n2 2\n1.0\n\x00\x00",
+		"#?RADIANCE\n\n-Y 100000000 +X 100000000\n",
+		"#?RADIANCE\n\n-Y 0 +X 8\n",
+		"#?RADIANCE\n\n-Y -1 +X 8\n",
+		"#?RADIANCE\n\n-Y 65536 +X 65536\n",
+		"PF\n100000000 100000000\n-1.0\n",
+		"PF\n9223372036854775807 2\n-1.0\n",
+		"Pf\n0 1\n-1.0\n",
+		"Pf\n1 1\nnan\n\x00\x00\x00\x00",
+	}
+
+	for _, input := range inputs {
+		if _, err := hdr.Decode(bytes.NewBufferString(input)); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
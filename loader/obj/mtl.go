@@ -0,0 +1,155 @@
+package obj
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+// mtlMaterial holds the subset of MTL parameters that can be mapped
+// onto the raytracing materials
+type mtlMaterial struct {
+	Kd    raytracing.Color
+	Ks    raytracing.Color
+	Ns    float64
+	Ni    float64
+	D     float64
+	Illum int
+}
+
+func (m mtlMaterial) material() raytracing.Material {
+	// transparent materials become glass
+	if m.D < 1 {
+		ior := m.Ni
+		if ior <= 0 {
+			ior = 1.5
+		}
+		return raytracing.Dielectric(ior)
+	}
+
+	// reflection illumination models or dominating specular color make a metal
+	reflective := m.Illum >= 3 && m.Illum <= 7
+	if reflective || maxComponent(m.Ks) > maxComponent(m.Kd) {
+		return raytracing.Metal(m.Ks, roughness(m.Ns))
+	}
+
+	return raytracing.Lambertian(m.Kd)
+}
+
+// roughness converts a Phong specular exponent (0..1000) to a roughness
+// value in [0, 1]
+func roughness(ns float64) float64 {
+	return math.Min(math.Sqrt(2/(ns+2)), 1)
+}
+
+func maxComponent(c raytracing.Color) float64 {
+	return math.Max(c.R, math.Max(c.G, c.B))
+}
+
+func ParseMTL(r io.Reader, name string) (map[string]raytracing.Material, error) {
+	materials := make(map[string]raytracing.Material)
+
+	var current *mtlMaterial
+	var currentName string
+	flush := func() {
+		if current != nil {
+			materials[currentName] = current.material()
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(stripComment(scanner.Text()))
+		if len(fields) == 0 {
+			continue
+		}
+
+		fail := func(err error) error {
+			return &ParseError{File: name, Line: line, Err: err}
+		}
+
+		keyword, args := fields[0], fields[1:]
+		if keyword == "newmtl" {
+			if len(args) != 1 {
+				return nil, fail(errArguments(keyword))
+			}
+			flush()
+			current = &mtlMaterial{Kd: raytracing.Color{R: 0.8, G: 0.8, B: 0.8}, Ns: 10, Ni: 1.5, D: 1, Illum: 2}
+			currentName = args[0]
+			continue
+		}
+
+		switch keyword {
+		case "Kd", "Ks", "Ns", "Ni", "d", "Tr", "illum":
+		default:
+			// maps, ambient color etc. are not supported
+			continue
+		}
+
+		if current == nil {
+			return nil, fail(errNoMaterial(keyword))
+		}
+
+		var err error
+		switch keyword {
+		case "Kd":
+			current.Kd, err = parseColor(args)
+		case "Ks":
+			current.Ks, err = parseColor(args)
+		case "Ns":
+			current.Ns, err = parseSingleFloat(args)
+		case "Ni":
+			current.Ni, err = parseSingleFloat(args)
+		case "d":
+			current.D, err = parseSingleFloat(args)
+		case "Tr":
+			var tr float64
+			tr, err = parseSingleFloat(args)
+			current.D = 1 - tr
+		case "illum":
+			var illum float64
+			illum, err = parseSingleFloat(args)
+			current.Illum = int(illum)
+		}
+		if err != nil {
+			return nil, fail(err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &ParseError{File: name, Line: line + 1, Err: err}
+	}
+
+	flush()
+	return materials, nil
+}
+
+func parseColor(args []string) (raytracing.Color, error) {
+	// "Kd spectral ..." and "Kd xyz ..." are not supported
+	if len(args) != 1 && len(args) != 3 {
+		return raytracing.Color{}, errArguments("color")
+	}
+	values, err := parseFloats(args)
+	if err != nil {
+		return raytracing.Color{}, err
+	}
+	if len(values) == 1 {
+		return raytracing.Color{R: values[0], G: values[0], B: values[0]}, nil
+	}
+	return raytracing.Color{R: values[0], G: values[1], B: values[2]}, nil
+}
+
+func parseSingleFloat(args []string) (float64, error) {
+	if len(args) != 1 {
+		return 0, errArguments("value")
+	}
+	values, err := parseFloats(args)
+	if err != nil {
+		return 0, err
+	}
+	return values[0], nil
+}
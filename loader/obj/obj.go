@@ -0,0 +1,372 @@
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+type Vec = raytracing.Vec
+
+type ParseError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func errArguments(keyword string) error {
+	return fmt.Errorf("wrong number of arguments for %q", keyword)
+}
+
+func errNoMaterial(keyword string) error {
+	return fmt.Errorf("%q before newmtl", keyword)
+}
+
+var defaultMaterial = raytracing.Lambertian(raytracing.Color{R: 0.8, G: 0.8, B: 0.8})
+
+// Load reads an OBJ file and the MTL files it references relative to its
+// directory
+func Load(path string) (raytracing.Hittable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	dir := filepath.Dir(path)
+	openFile := func(name string) (io.ReadCloser, error) {
+		return os.Open(filepath.Join(dir, name))
+	}
+
+	return Parse(file, filepath.Base(path), openFile)
+}
+
+// Parse reads OBJ data from r, using openFile to resolve material libraries.
+// Each combination of group and material becomes a separate mesh.
+func Parse(r io.Reader, name string, openFile func(name string) (io.ReadCloser, error)) (raytracing.Hittable, error) {
+	p := parser{
+		openFile:  openFile,
+		materials: make(map[string]raytracing.Material),
+		meshes:    make(map[meshKey]*meshBuilder),
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.line++
+		fields := strings.Fields(stripComment(scanner.Text()))
+		if len(fields) == 0 {
+			continue
+		}
+
+		err := p.parseStatement(fields[0], fields[1:])
+		if _, ok := err.(*ParseError); ok {
+			// errors in material libraries carry their own location
+			return nil, err
+		}
+		if err != nil {
+			return nil, &ParseError{File: name, Line: p.line, Err: err}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		// the scanner stopped on the line after the last one read
+		return nil, &ParseError{File: name, Line: p.line + 1, Err: err}
+	}
+
+	objects := make([]raytracing.Hittable, 0, len(p.order))
+	for _, key := range p.order {
+		mesh, err := p.meshes[key].build()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		objects = append(objects, mesh)
+	}
+
+	return raytracing.NewBVH(objects), nil
+}
+
+type meshKey struct {
+	group    string
+	material string
+}
+
+type parser struct {
+	line     int
+	openFile func(name string) (io.ReadCloser, error)
+
+	vertices  []Vec
+	normals   []Vec
+	texCoords [][2]float64
+
+	materials map[string]raytracing.Material
+	group     string
+	material  string
+
+	meshes map[meshKey]*meshBuilder
+	order  []meshKey
+}
+
+func (p *parser) parseStatement(keyword string, args []string) error {
+	switch keyword {
+	case "v":
+		v, err := parseVector(keyword, args)
+		if err != nil {
+			return err
+		}
+		p.vertices = append(p.vertices, v)
+	case "vn":
+		vn, err := parseVector(keyword, args)
+		if err != nil {
+			return err
+		}
+		p.normals = append(p.normals, vn.Normalized())
+	case "vt":
+		if len(args) < 1 || len(args) > 3 {
+			return errArguments(keyword)
+		}
+		values, err := parseFloats(args)
+		if err != nil {
+			return err
+		}
+		values = append(values, 0)
+		p.texCoords = append(p.texCoords, [2]float64{values[0], values[1]})
+	case "f":
+		return p.parseFace(args)
+	case "g", "o":
+		p.group = strings.Join(args, " ")
+	case "usemtl":
+		if len(args) != 1 {
+			return errArguments(keyword)
+		}
+		if _, ok := p.materials[args[0]]; !ok {
+			return fmt.Errorf("unknown material %q", args[0])
+		}
+		p.material = args[0]
+	case "mtllib":
+		if len(args) == 0 {
+			return errArguments(keyword)
+		}
+		for _, name := range args {
+			if err := p.loadMaterials(name); err != nil {
+				return err
+			}
+		}
+	}
+
+	// smoothing groups, lines, points etc. are ignored
+	return nil
+}
+
+func (p *parser) loadMaterials(name string) error {
+	if p.openFile == nil {
+		return fmt.Errorf("cannot open material library %q", name)
+	}
+
+	file, err := p.openFile(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	materials, err := ParseMTL(file, name)
+	if err != nil {
+		return err
+	}
+	for name, material := range materials {
+		p.materials[name] = material
+	}
+
+	return nil
+}
+
+type faceVertex struct {
+	vertex   int
+	texCoord int
+	normal   int
+}
+
+func (p *parser) parseFace(args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("face needs at least 3 vertices, got %d", len(args))
+	}
+
+	face := make([]faceVertex, len(args))
+	for i, arg := range args {
+		parts := strings.Split(arg, "/")
+		if len(parts) > 3 {
+			return fmt.Errorf("invalid face vertex %q", arg)
+		}
+
+		var err error
+		fv := faceVertex{texCoord: -1, normal: -1}
+		if fv.vertex, err = resolveIndex(parts[0], len(p.vertices)); err != nil {
+			return err
+		}
+		if len(parts) > 1 && parts[1] != "" {
+			if fv.texCoord, err = resolveIndex(parts[1], len(p.texCoords)); err != nil {
+				return err
+			}
+		}
+		if len(parts) > 2 && parts[2] != "" {
+			if fv.normal, err = resolveIndex(parts[2], len(p.normals)); err != nil {
+				return err
+			}
+		}
+		face[i] = fv
+	}
+
+	key := meshKey{p.group, p.material}
+	mesh, ok := p.meshes[key]
+	if !ok {
+		material := defaultMaterial
+		if p.material != "" {
+			material = p.materials[p.material]
+		}
+		mesh = &meshBuilder{material: material, lookup: make(map[faceVertex]int)}
+		p.meshes[key] = mesh
+		p.order = append(p.order, key)
+	}
+
+	// fan triangulation for convex polygons
+	for i := 1; i < len(face)-1; i++ {
+		mesh.addTriangle(p, face[0], face[i], face[i+1])
+	}
+
+	return nil
+}
+
+// resolveIndex converts a 1-based (or negative, relative) OBJ index
+// to a 0-based index
+func resolveIndex(s string, count int) (int, error) {
+	index, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid index %q", s)
+	}
+
+	if index < 0 {
+		index += count
+	} else {
+		index--
+	}
+	if index < 0 || index >= count {
+		return 0, fmt.Errorf("index %s out of range (%d defined)", s, count)
+	}
+
+	return index, nil
+}
+
+type meshBuilder struct {
+	material raytracing.Material
+	lookup   map[faceVertex]int
+
+	vertices     []Vec
+	normals      []Vec
+	texCoords    [][2]float64
+	indices      []int
+	hasNormals   bool
+	hasTexCoords bool
+}
+
+func (m *meshBuilder) addTriangle(p *parser, vertices ...faceVertex) {
+	for _, fv := range vertices {
+		index, ok := m.lookup[fv]
+		if !ok {
+			index = len(m.vertices)
+			m.lookup[fv] = index
+			m.vertices = append(m.vertices, p.vertices[fv.vertex])
+
+			normal := Vec{}
+			if fv.normal >= 0 {
+				normal = p.normals[fv.normal]
+				m.hasNormals = true
+			}
+			m.normals = append(m.normals, normal)
+
+			var texCoord [2]float64
+			if fv.texCoord >= 0 {
+				texCoord = p.texCoords[fv.texCoord]
+				m.hasTexCoords = true
+			}
+			m.texCoords = append(m.texCoords, texCoord)
+		}
+		m.indices = append(m.indices, index)
+	}
+}
+
+func (m *meshBuilder) build() (*raytracing.Mesh, error) {
+	var texCoords [][2]float64
+	if m.hasTexCoords {
+		texCoords = m.texCoords
+	}
+	if !m.hasNormals {
+		return raytracing.NewMesh(m.vertices, nil, texCoords, m.indices, m.material)
+	}
+
+	// vertices without normals get the area weighted normal of their faces
+	missing := make(map[int]Vec)
+	for i, normal := range m.normals {
+		if normal == (Vec{}) {
+			missing[i] = Vec{}
+		}
+	}
+	if len(missing) > 0 {
+		for i := 0; i+2 < len(m.indices); i += 3 {
+			a, b, c := m.vertices[m.indices[i]], m.vertices[m.indices[i+1]], m.vertices[m.indices[i+2]]
+			faceNormal := b.Subtract(a).Cross(c.Subtract(a))
+			for _, index := range m.indices[i : i+3] {
+				if sum, ok := missing[index]; ok {
+					missing[index] = sum.Add(faceNormal)
+				}
+			}
+		}
+		for index, sum := range missing {
+			m.normals[index] = sum.Normalized()
+		}
+	}
+
+	return raytracing.NewMesh(m.vertices, m.normals, texCoords, m.indices, m.material)
+}
+
+func parseVector(keyword string, args []string) (Vec, error) {
+	// an optional fourth (w) component is ignored
+	if len(args) != 3 && len(args) != 4 {
+		return Vec{}, errArguments(keyword)
+	}
+	values, err := parseFloats(args)
+	if err != nil {
+		return Vec{}, err
+	}
+	return Vec{X: values[0], Y: values[1], Z: values[2]}, nil
+}
+
+func parseFloats(args []string) ([]float64, error) {
+	values := make([]float64, len(args))
+	for i, arg := range args {
+		value, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", arg)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func stripComment(line string) string {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
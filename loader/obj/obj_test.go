@@ -0,0 +1,97 @@
+package obj_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/davherrmann/rtgo/loader/obj"
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+const quadOBJ = `
+# unit quad in the XY plane, written with negative indices
+mtllib quad.mtl
+o quad
+v -1 -1 0
+v 1 -1 0
+v 1 1 0
+v -1 1 0
+vn 0 0 1
+vt 0 0
+usemtl red
+f -4/-1/-1 -3/-1/-1 -2/-1/-1 -1/-1/-1
+`
+
+const quadMTL = `
+newmtl red
+Kd 1 0 0
+Ks 0 0 0
+Ns 10
+`
+
+func openFiles(files map[string]string) func(name string) (io.ReadCloser, error) {
+	return func(name string) (io.ReadCloser, error) {
+		content, ok := files[name]
+		if !ok {
+			return nil, errors.New("file not found: " + name)
+		}
+		return io.NopCloser(strings.NewReader(content)), nil
+	}
+}
+
+func TestParse(t *testing.T) {
+	hittable, err := obj.Parse(strings.NewReader(quadOBJ), "quad.obj", openFiles(map[string]string{"quad.mtl": quadMTL}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// both triangles of the quad must be hit
+	for _, x := range []float64{-0.5, 0.5} {
+		ray := raytracing.Ray{Origin: raytracing.Vec{X: x, Y: -x * 0.9, Z: 1}, Direction: raytracing.Vec{Z: -1}}
+		hit := hittable.Hit(ray, 0.001, 10000)
+		if hit == nil {
+			t.Fatalf("expected hit at x=%v", x)
+		}
+		if hit.Normal != (raytracing.Vec{Z: 1}) {
+			t.Errorf("expected normal %v, got %v", raytracing.Vec{Z: 1}, hit.Normal)
+		}
+		if hit.Material == nil {
+			t.Errorf("expected material")
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		Input string
+		Line  int
+	}{
+		{Input: "v 1 2\n", Line: 1},
+		{Input: "v 1 2 3\n\nv 1 2 x\n", Line: 3},
+		{Input: "v 1 2 3\nv 1 2 3\nv 1 2 3\nf 1 2 4\n", Line: 4},
+		{Input: "v 1 2 3\nf 1 1\n", Line: 2},
+		{Input: "usemtl missing\n", Line: 1},
+		{Input: "mtllib broken.mtl\n", Line: 2},
+		{Input: "v 1 2 3\nv " + strings.Repeat("1 ", bufio.MaxScanTokenSize) + "\n", Line: 2},
+		{Input: "mtllib long.mtl\n", Line: 2},
+	}
+
+	files := openFiles(map[string]string{
+		"broken.mtl": "newmtl a\nKd 1 x 1\n",
+		"long.mtl":   "newmtl a\nKd " + strings.Repeat("1 ", bufio.MaxScanTokenSize) + "\n",
+	})
+	for _, test := range tests {
+		_, err := obj.Parse(strings.NewReader(test.Input), "test.obj", files)
+
+		var parseError *obj.ParseError
+		if !errors.As(err, &parseError) {
+			t.Fatalf("%q: expected parse error, got %v", test.Input, err)
+		}
+		if parseError.Line != test.Line {
+			t.Errorf("%q: expected error on line %d, got %v", test.Input, test.Line, err)
+		}
+	}
+}
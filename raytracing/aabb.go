@@ -0,0 +1,80 @@
+package raytracing
+
+import "math"
+
+// AABB is an axis-aligned bounding box
+type AABB struct {
+	Min Vec
+	Max Vec
+}
+
+var emptyAABB = AABB{
+	Min: Vec{X: math.Inf(1), Y: math.Inf(1), Z: math.Inf(1)},
+	Max: Vec{X: math.Inf(-1), Y: math.Inf(-1), Z: math.Inf(-1)},
+}
+
+func (b AABB) Union(other AABB) AABB {
+	return AABB{
+		Min: b.Min.Min(other.Min),
+		Max: b.Max.Max(other.Max),
+	}
+}
+
+func (b AABB) Centroid() Vec {
+	return b.Min.Add(b.Max).Multiply(0.5)
+}
+
+func (b AABB) Extent() Vec {
+	return b.Max.Subtract(b.Min)
+}
+
+func (b AABB) SurfaceArea() float64 {
+	e := b.Extent()
+	if e.X < 0 || e.Y < 0 || e.Z < 0 {
+		return 0
+	}
+	return 2 * (e.X*e.Y + e.Y*e.Z + e.Z*e.X)
+}
+
+// Padded grows degenerate (flat) axes so that the slab test stays robust
+func (b AABB) Padded(delta float64) AABB {
+	e := b.Extent()
+	pad := func(min, max *float64, extent float64) {
+		if extent < delta {
+			*min -= delta / 2
+			*max += delta / 2
+		}
+	}
+	pad(&b.Min.X, &b.Max.X, e.X)
+	pad(&b.Min.Y, &b.Max.Y, e.Y)
+	pad(&b.Min.Z, &b.Max.Z, e.Z)
+	return b
+}
+
+// Hit uses the slab method to check whether the ray crosses the box
+// within [tMin, tMax]
+func (b AABB) Hit(ray Ray, tMin, tMax float64) bool {
+	for axis := 0; axis < 3; axis++ {
+		invD := 1 / ray.Direction.Axis(axis)
+		origin := ray.Origin.Axis(axis)
+
+		t0 := (b.Min.Axis(axis) - origin) * invD
+		t1 := (b.Max.Axis(axis) - origin) * invD
+		if invD < 0 {
+			t0, t1 = t1, t0
+		}
+
+		// written so that NaNs (0 * Inf) never shrink the interval
+		if t0 > tMin {
+			tMin = t0
+		}
+		if t1 < tMax {
+			tMax = t1
+		}
+		if tMax < tMin {
+			return false
+		}
+	}
+
+	return true
+}
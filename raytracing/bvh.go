@@ -0,0 +1,211 @@
+package raytracing
+
+import (
+	"math"
+	"sort"
+)
+
+// BVHNode is a node of a bounding volume hierarchy, split using the
+// surface area heuristic (SAH)
+type BVHNode struct {
+	Left  Hittable
+	Right Hittable
+	Box   AABB
+}
+
+const (
+	sahBuckets          = 12
+	sahTraversalCost    = 0.125
+	sahIntersectionCost = 1.0
+)
+
+func NewBVH(objects []Hittable) Hittable {
+	switch len(objects) {
+	case 0:
+		return World{}
+	case 1:
+		return objects[0]
+	}
+
+	// don't reorder the caller's slice
+	items := make([]bvhItem, 0, len(objects))
+	var unbounded []Hittable
+	for _, object := range objects {
+		box := object.BoundingBox()
+		centroid := box.Centroid()
+		switch {
+		case box.Min.X > box.Max.X || box.Min.Y > box.Max.Y || box.Min.Z > box.Max.Z:
+			// empty objects, like a world without objects, can't be hit
+		case !finite(centroid):
+			// infinite boxes can't be sorted into buckets
+			unbounded = append(unbounded, object)
+		default:
+			items = append(items, bvhItem{object, box, centroid})
+		}
+	}
+
+	var bvh Hittable = World{}
+	if len(items) > 0 {
+		bvh = buildBVH(items)
+	}
+	if len(unbounded) > 0 {
+		return World{Objects: append(unbounded, bvh)}
+	}
+	return bvh
+}
+
+func finite(v Vec) bool {
+	for _, c := range []float64{v.X, v.Y, v.Z} {
+		if math.IsNaN(c) || math.IsInf(c, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w World) BVH() Hittable {
+	return NewBVH(w.Objects)
+}
+
+type bvhItem struct {
+	object   Hittable
+	box      AABB
+	centroid Vec
+}
+
+func buildBVH(items []bvhItem) Hittable {
+	if len(items) == 1 {
+		return items[0].object
+	}
+
+	box := emptyAABB
+	centroidBox := emptyAABB
+	for _, item := range items {
+		box = box.Union(item.box)
+		centroidBox = centroidBox.Union(AABB{item.centroid, item.centroid})
+	}
+
+	// split along the axis with the largest centroid spread
+	extent := centroidBox.Extent()
+	axis := 0
+	if extent.Y > extent.X {
+		axis = 1
+	}
+	if extent.Z > extent.Axis(axis) {
+		axis = 2
+	}
+
+	mid := sahSplit(items, box, centroidBox, axis)
+	if mid <= 0 || mid >= len(items) {
+		// all centroids coincide or SAH found no useful split: median split
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].centroid.Axis(axis) < items[j].centroid.Axis(axis)
+		})
+		mid = len(items) / 2
+	}
+
+	return &BVHNode{
+		Left:  buildBVH(items[:mid]),
+		Right: buildBVH(items[mid:]),
+		Box:   box,
+	}
+}
+
+// sahSplit partitions items in place and returns the index of the first item
+// of the right child, or -1 if no split is cheaper than the median split
+func sahSplit(items []bvhItem, box, centroidBox AABB, axis int) int {
+	if len(items) <= 2 {
+		return -1
+	}
+
+	min := centroidBox.Min.Axis(axis)
+	max := centroidBox.Max.Axis(axis)
+	if max <= min {
+		return -1
+	}
+
+	bucketOf := func(item bvhItem) int {
+		b := int(sahBuckets * (item.centroid.Axis(axis) - min) / (max - min))
+		if b >= sahBuckets {
+			b = sahBuckets - 1
+		}
+		if b < 0 {
+			b = 0
+		}
+		return b
+	}
+
+	var counts [sahBuckets]int
+	var boxes [sahBuckets]AABB
+	for i := range boxes {
+		boxes[i] = emptyAABB
+	}
+	for _, item := range items {
+		b := bucketOf(item)
+		counts[b]++
+		boxes[b] = boxes[b].Union(item.box)
+	}
+
+	// cost of splitting after bucket i
+	bestCost := -1.0
+	bestBucket := 0
+	for i := 0; i < sahBuckets-1; i++ {
+		left, right := emptyAABB, emptyAABB
+		leftCount, rightCount := 0, 0
+		for j := 0; j <= i; j++ {
+			left = left.Union(boxes[j])
+			leftCount += counts[j]
+		}
+		for j := i + 1; j < sahBuckets; j++ {
+			right = right.Union(boxes[j])
+			rightCount += counts[j]
+		}
+		if leftCount == 0 || rightCount == 0 {
+			continue
+		}
+
+		cost := sahTraversalCost + sahIntersectionCost*
+			(float64(leftCount)*left.SurfaceArea()+float64(rightCount)*right.SurfaceArea())/box.SurfaceArea()
+		if bestCost < 0 || cost < bestCost {
+			bestCost = cost
+			bestBucket = i
+		}
+	}
+
+	if bestCost < 0 {
+		return -1
+	}
+
+	// partition
+	mid := 0
+	for i := range items {
+		if bucketOf(items[i]) <= bestBucket {
+			items[i], items[mid] = items[mid], items[i]
+			mid++
+		}
+	}
+
+	return mid
+}
+
+func (n *BVHNode) Hit(ray Ray, tMin, tMax float64) *Hit {
+	if !n.Box.Hit(ray, tMin, tMax) {
+		return nil
+	}
+
+	leftHit := n.Left.Hit(ray, tMin, tMax)
+	if leftHit != nil {
+		tMax = leftHit.T
+	}
+
+	rightHit := n.Right.Hit(ray, tMin, tMax)
+	if rightHit != nil {
+		return rightHit
+	}
+
+	return leftHit
+}
+
+func (n *BVHNode) BoundingBox() AABB {
+	return n.Box
+}
@@ -0,0 +1,88 @@
+package raytracing_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func randomSpheres(rng *rand.Rand, n int) []raytracing.Hittable {
+	spheres := make([]raytracing.Hittable, n)
+	for i := range spheres {
+		spheres[i] = raytracing.Sphere{
+			Center: Vec{X: rng.Float64()*20 - 10, Y: rng.Float64()*20 - 10, Z: rng.Float64()*20 - 10},
+			Radius: rng.Float64()*0.5 + 0.05,
+		}
+	}
+	return spheres
+}
+
+func TestBVHMatchesWorld(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	spheres := randomSpheres(rng, 500)
+
+	world := raytracing.World{Objects: spheres}
+	bvh := world.BVH()
+
+	for i := 0; i < 2000; i++ {
+		ray := raytracing.Ray{
+			Origin:    Vec{X: rng.Float64()*30 - 15, Y: rng.Float64()*30 - 15, Z: rng.Float64()*30 - 15},
+			Direction: Vec{X: rng.Float64()*2 - 1, Y: rng.Float64()*2 - 1, Z: rng.Float64()*2 - 1},
+		}
+
+		expected := world.Hit(ray, 0.001, 10000)
+		actual := bvh.Hit(ray, 0.001, 10000)
+
+		if (expected == nil) != (actual == nil) {
+			t.Fatalf("ray %#v: expected hit %v, got %v", ray, expected != nil, actual != nil)
+		}
+		if expected != nil {
+			requireEqual(t, actual.T, expected.T)
+		}
+	}
+}
+
+func TestBVHBoundingBox(t *testing.T) {
+	spheres := []raytracing.Hittable{
+		raytracing.Sphere{Center: Vec{X: -1}, Radius: 1},
+		raytracing.Sphere{Center: Vec{Y: 2}, Radius: -0.5},
+	}
+
+	box := raytracing.NewBVH(spheres).BoundingBox()
+
+	requireEqual(t, box.Min, Vec{X: -2, Y: -1, Z: -1})
+	requireEqual(t, box.Max, Vec{X: 0.5, Y: 2.5, Z: 1})
+}
+
+func TestBVHEmptyObjects(t *testing.T) {
+	objects := []raytracing.Hittable{
+		raytracing.Sphere{Center: Vec{X: -2}, Radius: 0.5},
+		raytracing.World{},
+		raytracing.Sphere{Center: Vec{X: 2}, Radius: 0.5},
+		&raytracing.Mesh{},
+		raytracing.Sphere{Center: Vec{Z: -2}, Radius: 0.5},
+	}
+
+	bvh := raytracing.NewBVH(objects)
+
+	hit := bvh.Hit(raytracing.Ray{Origin: Vec{X: 5}, Direction: Vec{X: -1}}, 0.001, 10000)
+	if hit == nil {
+		t.Fatal("expected hit")
+	}
+	requireEqual(t, hit.T, 2.5)
+
+	box := bvh.BoundingBox()
+	requireEqual(t, box.Min, Vec{X: -2.5, Y: -0.5, Z: -2.5})
+	requireEqual(t, box.Max, Vec{X: 2.5, Y: 0.5, Z: 0.5})
+}
+
+func BenchmarkBVH(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	bvh := raytracing.NewBVH(randomSpheres(rng, 5000))
+	ray := raytracing.Ray{Origin: Vec{Z: -20}, Direction: Vec{Z: 1}}
+
+	for i := 0; i < b.N; i++ {
+		bvh.Hit(ray, 0.001, 10000)
+	}
+}
@@ -1,37 +1,119 @@
 package raytracing
 
+import "math"
+
 type Camera struct {
 	Up     Vec
 	From   Vec
 	LookAt Vec
 
-	Zoom float64
+	// Projection defaults to a perspective projection with a viewport of
+	// height Zoom at distance one
+	Projection Projection
+	Zoom       float64
+
+	// ShutterOpen and ShutterClose span the time interval rays are sent in,
+	// objects moving within it are motion blurred
+	ShutterOpen  float64
+	ShutterClose float64
+
+	// Aperture is the diameter of the lens, zero is a pinhole camera
+	Aperture float64
+	// FocusDistance from From to the plane in focus, zero means 1
+	FocusDistance float64
+	// AutoFocus focuses on LookAt instead of FocusDistance
+	AutoFocus bool
+	// ApertureBlades shapes the lens, and with it the bokeh, as a regular
+	// polygon, fewer than three is a circle
+	ApertureBlades   int
+	ApertureRotation float64
+}
+
+func (c *Camera) focusDistance() float64 {
+	if c.AutoFocus {
+		return c.LookAt.Subtract(c.From).Length()
+	}
+	if c.FocusDistance <= 0 {
+		return 1
+	}
+	return c.FocusDistance
+}
+
+func (c *Camera) projection() Projection {
+	if c.Projection == nil {
+		// Zoom is the viewport height at distance one
+		return Perspective{VerticalFOV: 2 * math.Atan(c.Zoom/2)}
+	}
+	return c.Projection
 }
 
 func (c *Camera) RayCaster(aspectRatio float64) RayCaster {
-	// camera & viewport
-	viewportHeight := c.Zoom
-	viewportWidth := aspectRatio * viewportHeight
-
-	w := c.From.Subtract(c.LookAt).Normalized()
-	u := c.Up.Cross(w).Normalized()
-	v := w.Cross(u)
-
-	// ray origin
-	origin := c.From
-	horizontal := u.Multiply(viewportWidth)
-	vertical := v.Multiply(viewportHeight)
-	lowerLeftCorner := origin. //
-					Subtract(horizontal.Multiply(0.5)). //
-					Subtract(vertical.Multiply(0.5)).   //
-					Subtract(w)
-
-	return func(u, v float64) Ray {
-		rayDirection := lowerLeftCorner. //
-							Add(horizontal.Multiply(u)). //
-							Add(vertical.Multiply(v)).   //
-							Subtract(origin)
-
-		return Ray{origin, rayDirection}
+	projection := c.projection()
+
+	basis := ONB{
+		W: c.From.Subtract(c.LookAt).Normalized(),
 	}
+	basis.U = c.Up.Cross(basis.W).Normalized()
+	basis.V = basis.W.Cross(basis.U)
+
+	focus := c.focusDistance()
+	lensRadius := c.Aperture / 2
+
+	return func(s, t float64, sampler Sampler) Ray {
+		time := c.ShutterOpen
+		if c.ShutterClose > c.ShutterOpen {
+			time += sampler.Float64() * (c.ShutterClose - c.ShutterOpen)
+		}
+
+		origin, direction := projection.Project(s, t, aspectRatio)
+		if direction == (Vec{}) {
+			return Ray{Origin: c.From, Time: time, Sampler: sampler}
+		}
+
+		// rays through the lens meet on the focus plane
+		if lensRadius > 0 && direction.Z < 0 {
+			focusPoint := origin.Add(direction.Multiply(focus / -direction.Z))
+			x, y := c.sampleAperture(sampler)
+			origin = origin.Add(Vec{X: x * lensRadius, Y: y * lensRadius})
+			direction = focusPoint.Subtract(origin)
+		}
+
+		return Ray{c.From.Add(basis.Local(origin)), basis.Local(direction), time, sampler}
+	}
+}
+
+// sampleAperture returns a uniformly distributed point on the unit disk,
+// or on the polygon inscribed in it
+func (c *Camera) sampleAperture(sampler Sampler) (x, y float64) {
+	if c.ApertureBlades < 3 {
+		// concentric mapping avoids rejection sampling
+		a := 2*sampler.Float64() - 1
+		b := 2*sampler.Float64() - 1
+		if a == 0 && b == 0 {
+			return 0, 0
+		}
+
+		var r, phi float64
+		if math.Abs(a) > math.Abs(b) {
+			r, phi = a, math.Pi/4*(b/a)
+		} else {
+			r, phi = b, math.Pi/2-math.Pi/4*(a/b)
+		}
+		return r * math.Cos(phi), r * math.Sin(phi)
+	}
+
+	// pick one of the equal triangles between the center and two corners
+	blade := sampleIndex(sampler, c.ApertureBlades)
+	step := 2 * math.Pi / float64(c.ApertureBlades)
+	phi0 := c.ApertureRotation + float64(blade)*step
+	phi1 := phi0 + step
+
+	// uniform barycentric coordinates
+	a := sampler.Float64()
+	b := sampler.Float64()
+	if a+b > 1 {
+		a, b = 1-a, 1-b
+	}
+
+	return a*math.Cos(phi0) + b*math.Cos(phi1), a*math.Sin(phi0) + b*math.Sin(phi1)
 }
@@ -0,0 +1,49 @@
+package raytracing_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func TestCameraDepthOfField(t *testing.T) {
+	for _, blades := range []int{0, 6} {
+		camera := raytracing.Camera{
+			Up:             Vec{Y: 1},
+			From:           Vec{Z: 5},
+			LookAt:         Vec{},
+			Zoom:           1,
+			Aperture:       0.5,
+			AutoFocus:      true,
+			ApertureBlades: blades,
+		}
+		rayCaster := camera.RayCaster(1)
+		rng := raytracing.NewRNG(1)
+
+		for i := 0; i < 100; i++ {
+			ray := rayCaster(0.7, 0.4, rng)
+
+			// rays start on the lens
+			offset := ray.Origin.Subtract(camera.From)
+			if offset.Length() > 0.25+1e-9 || math.Abs(offset.Z) > 1e-9 {
+				t.Fatalf("blades %d: expected ray origin on the lens, got %v", blades, ray.Origin)
+			}
+
+			// and meet on the focus plane at z = 0
+			onFocusPlane := ray.Origin.Add(ray.Direction.Multiply(-ray.Origin.Z / ray.Direction.Z))
+			requireEqual(t, onFocusPlane.X, 5*0.2)
+			requireEqual(t, onFocusPlane.Y, 5*-0.1)
+		}
+	}
+}
+
+func TestCameraFocusKeepsFieldOfView(t *testing.T) {
+	camera := raytracing.Camera{Up: Vec{Y: 1}, From: Vec{Z: 5}, LookAt: Vec{}, Zoom: 1}
+	pinhole := camera.RayCaster(1)(0.2, 0.9, raytracing.NewRNG(1))
+
+	camera.FocusDistance = 3
+	focused := camera.RayCaster(1)(0.2, 0.9, raytracing.NewRNG(1))
+
+	requireEqual(t, pinhole.Direction.Normalized().Dot(focused.Direction.Normalized()), 1)
+}
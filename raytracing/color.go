@@ -1,6 +1,9 @@
 package raytracing
 
-var Black = Color{}
+var (
+	Black = Color{}
+	White = Color{1, 1, 1}
+)
 
 type Color struct {
 	R float64
@@ -0,0 +1,174 @@
+package raytracing
+
+import (
+	"math"
+	"sort"
+)
+
+// HDRImage holds linear radiance values, row by row from the top
+type HDRImage struct {
+	Width  int
+	Height int
+	Pixels []Color
+}
+
+func (img *HDRImage) At(x, y int) Color {
+	return img.Pixels[y*img.Width+x]
+}
+
+// EnvironmentMap lights the scene with an equirectangular image surrounding
+// it. Use its Background method as the scene background and add it to the
+// lights so bright texels are importance sampled.
+type EnvironmentMap struct {
+	Image *HDRImage
+	// Rotation around the Y axis in radians
+	Rotation  float64
+	Intensity float64
+
+	// cumulative distributions of texel luminance, over rows (marginal) and
+	// over the columns of each row (conditional)
+	marginal    []float64
+	conditional [][]float64
+	rowWeights  []float64
+	total       float64
+}
+
+func NewEnvironmentMap(image *HDRImage, rotation, intensity float64) *EnvironmentMap {
+	env := &EnvironmentMap{
+		Image:       image,
+		Rotation:    rotation,
+		Intensity:   intensity,
+		marginal:    make([]float64, image.Height),
+		conditional: make([][]float64, image.Height),
+		rowWeights:  make([]float64, image.Height),
+	}
+
+	for y := 0; y < image.Height; y++ {
+		// rows near the poles cover a smaller solid angle
+		sinTheta := math.Sin(math.Pi * (float64(y) + 0.5) / float64(image.Height))
+
+		row := make([]float64, image.Width)
+		sum := 0.0
+		for x := 0; x < image.Width; x++ {
+			sum += luminance(image.At(x, y)) * sinTheta
+			row[x] = sum
+		}
+		normalize(row)
+
+		env.conditional[y] = row
+		env.rowWeights[y] = sum
+		env.total += sum
+		env.marginal[y] = env.total
+	}
+	normalize(env.marginal)
+
+	return env
+}
+
+func luminance(c Color) float64 {
+	return 0.2126*c.R + 0.7152*c.G + 0.0722*c.B
+}
+
+func normalize(cdf []float64) {
+	if len(cdf) == 0 || cdf[len(cdf)-1] == 0 {
+		return
+	}
+	total := cdf[len(cdf)-1]
+	for i := range cdf {
+		cdf[i] /= total
+	}
+}
+
+// texel returns the texel a direction points to
+func (e *EnvironmentMap) texel(direction Vec) (x, y int) {
+	direction = direction.Normalized()
+	phi := math.Atan2(direction.Z, direction.X) - e.Rotation
+	theta := math.Acos(math.Max(-1, math.Min(1, direction.Y)))
+
+	u := phi / (2 * math.Pi)
+	u -= math.Floor(u)
+	v := theta / math.Pi
+
+	x = int(u * float64(e.Image.Width))
+	y = int(v * float64(e.Image.Height))
+	if x >= e.Image.Width {
+		x = e.Image.Width - 1
+	}
+	if y >= e.Image.Height {
+		y = e.Image.Height - 1
+	}
+	return x, y
+}
+
+func (e *EnvironmentMap) Background(direction Vec) Color {
+	if e.Image.Width == 0 || e.Image.Height == 0 {
+		return Black
+	}
+	return e.Image.At(e.texel(direction)).Multiply(e.Intensity)
+}
+
+func (e *EnvironmentMap) Sample(point Vec, sampler Sampler) LightSample {
+	if e.total == 0 {
+		return LightSample{}
+	}
+
+	y := searchCDF(e.marginal, sampler.Float64())
+	x := searchCDF(e.conditional[y], sampler.Float64())
+
+	// uniformly within the texel
+	u := (float64(x) + sampler.Float64()) / float64(e.Image.Width)
+	v := (float64(y) + sampler.Float64()) / float64(e.Image.Height)
+	phi := 2*math.Pi*u + e.Rotation
+	theta := math.Pi * v
+	direction := Vec{
+		X: math.Sin(theta) * math.Cos(phi),
+		Y: math.Cos(theta),
+		Z: math.Sin(theta) * math.Sin(phi),
+	}
+
+	pdf := e.texelPDF(x, y, math.Sin(theta))
+	if pdf == 0 {
+		return LightSample{}
+	}
+
+	return LightSample{
+		Direction: direction,
+		Distance:  math.Inf(1),
+		Radiance:  e.Image.At(x, y).Multiply(e.Intensity),
+		PDF:       pdf,
+	}
+}
+
+func (e *EnvironmentMap) PDF(point, direction Vec) float64 {
+	if e.total == 0 {
+		return 0
+	}
+	x, y := e.texel(direction)
+	sinTheta := math.Sqrt(math.Max(0, 1-math.Pow(direction.Normalized().Y, 2)))
+	return e.texelPDF(x, y, sinTheta)
+}
+
+// texelPDF converts the probability of picking a texel to solid angle
+func (e *EnvironmentMap) texelPDF(x, y int, sinTheta float64) float64 {
+	if sinTheta <= 0 {
+		return 0
+	}
+
+	rowProbability := e.rowWeights[y] / e.total
+	columnProbability := e.conditional[y][x]
+	if x > 0 {
+		columnProbability -= e.conditional[y][x-1]
+	}
+
+	texels := float64(e.Image.Width * e.Image.Height)
+	return rowProbability * columnProbability * texels / (2 * math.Pi * math.Pi * sinTheta)
+}
+
+// searchCDF returns the first index whose cumulative probability exceeds u
+func searchCDF(cdf []float64, u float64) int {
+	i := sort.Search(len(cdf), func(i int) bool { return cdf[i] > u })
+	if i == len(cdf) {
+		i--
+	}
+	return i
+}
@@ -0,0 +1,48 @@
+package raytracing_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func TestEnvironmentMapSampling(t *testing.T) {
+	// dark image with a single bright texel
+	img := &raytracing.HDRImage{Width: 16, Height: 8, Pixels: make([]raytracing.Color, 16*8)}
+	for i := range img.Pixels {
+		img.Pixels[i] = raytracing.Color{R: 0.1, G: 0.1, B: 0.1}
+	}
+	img.Pixels[3*16+5] = raytracing.Color{R: 100, G: 100, B: 100}
+
+	env := raytracing.NewEnvironmentMap(img, 0.7, 2)
+	rng := raytracing.NewRNG(1)
+
+	bright := 0
+	for i := 0; i < 1000; i++ {
+		sample := env.Sample(Vec{}, rng)
+		requireEqual(t, env.PDF(Vec{}, sample.Direction), sample.PDF)
+		requireEqual(t, sample.Direction.Length(), 1)
+
+		background := env.Background(sample.Direction)
+		if background != sample.Radiance {
+			t.Fatalf("expected sample radiance %v to match background %v", sample.Radiance, background)
+		}
+		if background.R > 1 {
+			bright++
+		}
+	}
+	if bright < 900 {
+		t.Errorf("expected most samples to hit the bright texel, got %d of 1000", bright)
+	}
+
+	// the density integrates to one over the sphere
+	const samples = 200000
+	sum := 0.0
+	for i := 0; i < samples; i++ {
+		sum += env.PDF(Vec{}, raytracing.UniformSpherePDF{}.Generate(rng)) * 4 * math.Pi
+	}
+	if integral := sum / samples; math.Abs(integral-1) > 0.05 {
+		t.Errorf("expected pdf to integrate to 1, got %v", integral)
+	}
+}
@@ -0,0 +1,262 @@
+package raytracing
+
+import "math"
+
+// Light can be sampled directly from a shading point (next-event estimation).
+// Area lights are Hittables as well and have to be added to the world too.
+type Light interface {
+	Sample(point Vec, sampler Sampler) LightSample
+	// PDF is the solid angle density of Sample choosing direction from point,
+	// zero for lights that can't be hit by rays
+	PDF(point, direction Vec) float64
+}
+
+type LightSample struct {
+	// Direction is normalized and points towards the light
+	Direction Vec
+	// Distance is infinite for lights at infinity
+	Distance float64
+	Radiance Color
+	PDF      float64
+	// Delta is set for point-like lights that can only be reached by sampling
+	Delta bool
+}
+
+type PointLight struct {
+	Position  Vec
+	Color     Color
+	Intensity float64
+}
+
+func (l PointLight) Sample(point Vec, sampler Sampler) LightSample {
+	toLight := l.Position.Subtract(point)
+	distanceSquared := toLight.LengthSquared()
+
+	return LightSample{
+		Direction: toLight.Normalized(),
+		Distance:  math.Sqrt(distanceSquared),
+		Radiance:  l.Color.Multiply(l.Intensity / distanceSquared),
+		PDF:       1,
+		Delta:     true,
+	}
+}
+
+func (l PointLight) PDF(point, direction Vec) float64 {
+	return 0
+}
+
+// SpotLight is a point light limited to a cone of half-angle Angle, fading
+// out smoothly over the outer Softness fraction of the cone
+type SpotLight struct {
+	Position  Vec
+	Direction Vec
+	Angle     float64
+	Softness  float64
+	Color     Color
+	Intensity float64
+}
+
+func (l SpotLight) Sample(point Vec, sampler Sampler) LightSample {
+	sample := PointLight{l.Position, l.Color, l.Intensity}.Sample(point, sampler)
+
+	cosOuter := math.Cos(l.Angle)
+	cosInner := math.Cos(l.Angle * (1 - l.Softness))
+	cosTheta := sample.Direction.Multiply(-1).Dot(l.Direction.Normalized())
+
+	falloff := 1.0
+	if cosTheta < cosInner {
+		t := math.Max(0, (cosTheta-cosOuter)/(cosInner-cosOuter))
+		falloff = t * t * (3 - 2*t)
+	}
+	sample.Radiance = sample.Radiance.Multiply(falloff)
+
+	return sample
+}
+
+func (l SpotLight) PDF(point, direction Vec) float64 {
+	return 0
+}
+
+// DirectionalLight is infinitely far away, like the sun. Direction is the
+// direction the light travels in, Color times Intensity the irradiance
+// perpendicular to it.
+type DirectionalLight struct {
+	Direction Vec
+	Color     Color
+	Intensity float64
+	// AngularRadius spreads the light over a small disk in the sky, which
+	// softens shadows and lets rays hit it. Zero is a perfectly sharp light.
+	AngularRadius float64
+}
+
+// solidAngle is the solid angle of the light's disk
+func (l DirectionalLight) solidAngle() float64 {
+	return 2 * math.Pi * (1 - math.Cos(l.AngularRadius))
+}
+
+// inDisk reports whether direction points into the light's disk
+func (l DirectionalLight) inDisk(direction Vec) bool {
+	toLight := l.Direction.Normalized().Multiply(-1)
+	return l.AngularRadius > 0 && direction.Normalized().Dot(toLight) >= math.Cos(l.AngularRadius)
+}
+
+// Radiance of the light's disk, zero for sharp lights
+func (l DirectionalLight) Radiance(direction Vec) Color {
+	if !l.inDisk(direction) {
+		return Black
+	}
+	return l.Color.Multiply(l.Intensity / l.solidAngle())
+}
+
+func (l DirectionalLight) Sample(point Vec, sampler Sampler) LightSample {
+	toLight := l.Direction.Normalized().Multiply(-1)
+	if l.AngularRadius <= 0 {
+		return LightSample{
+			Direction: toLight,
+			Distance:  math.Inf(1),
+			Radiance:  l.Color.Multiply(l.Intensity),
+			PDF:       1,
+			Delta:     true,
+		}
+	}
+
+	cosThetaMax := math.Cos(l.AngularRadius)
+	cosTheta := 1 - sampler.Float64()*(1-cosThetaMax)
+	sinTheta := math.Sqrt(math.Max(0, 1-cosTheta*cosTheta))
+	phi := 2 * math.Pi * sampler.Float64()
+
+	return LightSample{
+		Direction: NewONB(toLight).Local(Vec{X: math.Cos(phi) * sinTheta, Y: math.Sin(phi) * sinTheta, Z: cosTheta}),
+		Distance:  math.Inf(1),
+		Radiance:  l.Color.Multiply(l.Intensity / l.solidAngle()),
+		PDF:       1 / l.solidAngle(),
+	}
+}
+
+func (l DirectionalLight) PDF(point, direction Vec) float64 {
+	if !l.inDisk(direction) {
+		return 0
+	}
+	return 1 / l.solidAngle()
+}
+
+// SphereLight is an emissive sphere, sampled uniformly within the cone
+// it subtends
+type SphereLight struct {
+	Sphere
+	radiance Color
+}
+
+func NewSphereLight(center Vec, radius float64, color Color, intensity float64) SphereLight {
+	return SphereLight{
+		Sphere: Sphere{
+			Center:   center,
+			Radius:   radius,
+			Material: DiffuseLight(color, intensity),
+		},
+		radiance: color.Multiply(intensity),
+	}
+}
+
+// cosThetaMax returns the cosine of the half-angle of the cone subtended
+// by the sphere, false if point lies inside of it
+func (l SphereLight) cosThetaMax(point Vec) (float64, bool) {
+	distanceSquared := l.Center.Subtract(point).LengthSquared()
+	radiusSquared := l.Radius * l.Radius
+	if distanceSquared <= radiusSquared {
+		return 0, false
+	}
+	return math.Sqrt(1 - radiusSquared/distanceSquared), true
+}
+
+func (l SphereLight) Sample(point Vec, sampler Sampler) LightSample {
+	cosThetaMax, ok := l.cosThetaMax(point)
+	if !ok {
+		return LightSample{}
+	}
+
+	basis := NewONB(l.Center.Subtract(point))
+
+	cosTheta := 1 - sampler.Float64()*(1-cosThetaMax)
+	sinTheta := math.Sqrt(math.Max(0, 1-cosTheta*cosTheta))
+	phi := 2 * math.Pi * sampler.Float64()
+	direction := basis.Local(Vec{X: math.Cos(phi) * sinTheta, Y: math.Sin(phi) * sinTheta, Z: cosTheta})
+
+	hit := l.Sphere.Hit(Ray{Origin: point, Direction: direction}, 0.001, math.Inf(1))
+	if hit == nil {
+		return LightSample{}
+	}
+
+	return LightSample{
+		Direction: direction,
+		Distance:  hit.T,
+		Radiance:  l.radiance,
+		PDF:       1 / (2 * math.Pi * (1 - cosThetaMax)),
+	}
+}
+
+func (l SphereLight) PDF(point, direction Vec) float64 {
+	cosThetaMax, ok := l.cosThetaMax(point)
+	if !ok {
+		return 0
+	}
+	if l.Sphere.Hit(Ray{Origin: point, Direction: direction}, 0.001, math.Inf(1)) == nil {
+		return 0
+	}
+	return 1 / (2 * math.Pi * (1 - cosThetaMax))
+}
+
+// QuadLight is an emissive parallelogram, sampled uniformly by area
+type QuadLight struct {
+	Quad
+	radiance Color
+}
+
+func NewQuadLight(q, u, v Vec, color Color, intensity float64) QuadLight {
+	return QuadLight{
+		Quad: Quad{
+			Q:        q,
+			U:        u,
+			V:        v,
+			Material: DiffuseLight(color, intensity),
+		},
+		radiance: color.Multiply(intensity),
+	}
+}
+
+// solidAnglePDF converts the area density 1/A to solid angle
+func (l QuadLight) solidAnglePDF(toLight Vec) float64 {
+	normal := l.U.Cross(l.V)
+	area := normal.Length()
+	distanceSquared := toLight.LengthSquared()
+	cosine := math.Abs(normal.Dot(toLight)) / (area * math.Sqrt(distanceSquared))
+	if cosine < 1e-8 {
+		return 0
+	}
+	return distanceSquared / (cosine * area)
+}
+
+func (l QuadLight) Sample(point Vec, sampler Sampler) LightSample {
+	onLight := l.Q.Add(l.U.Multiply(sampler.Float64())).Add(l.V.Multiply(sampler.Float64()))
+	toLight := onLight.Subtract(point)
+
+	pdf := l.solidAnglePDF(toLight)
+	if pdf == 0 {
+		return LightSample{}
+	}
+
+	return LightSample{
+		Direction: toLight.Normalized(),
+		Distance:  toLight.Length(),
+		Radiance:  l.radiance,
+		PDF:       pdf,
+	}
+}
+
+func (l QuadLight) PDF(point, direction Vec) float64 {
+	hit := l.Quad.Hit(Ray{Origin: point, Direction: direction}, 0.001, math.Inf(1))
+	if hit == nil {
+		return 0
+	}
+	return l.solidAnglePDF(hit.Point.Subtract(point))
+}
@@ -0,0 +1,59 @@
+package raytracing_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func TestAreaLightPDF(t *testing.T) {
+	white := raytracing.Color{R: 1, G: 1, B: 1}
+	lights := []raytracing.Light{
+		raytracing.NewQuadLight(Vec{X: -1, Y: 3, Z: -1}, Vec{X: 2}, Vec{Z: 2}, white, 1),
+		raytracing.NewSphereLight(Vec{Y: 3}, 0.5, white, 1),
+	}
+	point := Vec{X: 0.3, Z: -0.2}
+	rng := raytracing.NewRNG(1)
+
+	for _, light := range lights {
+		for i := 0; i < 100; i++ {
+			sample := light.Sample(point, rng)
+			if sample.PDF <= 0 {
+				t.Fatalf("%T: expected positive pdf", light)
+			}
+			requireEqual(t, light.PDF(point, sample.Direction), sample.PDF)
+			requireEqual(t, sample.Direction.Length(), 1)
+		}
+
+		if pdf := light.PDF(point, Vec{Y: -1}); pdf != 0 {
+			t.Errorf("%T: expected zero pdf away from the light, got %v", light, pdf)
+		}
+	}
+}
+
+func TestSpotLight(t *testing.T) {
+	rng := raytracing.NewRNG(1)
+	spot := raytracing.SpotLight{
+		Position:  Vec{Y: 2},
+		Direction: Vec{Y: -1},
+		Angle:     math.Pi / 4,
+		Softness:  0.5,
+		Color:     raytracing.Color{R: 1, G: 1, B: 1},
+		Intensity: 4,
+	}
+
+	tests := []struct {
+		Point    Vec
+		Radiance float64
+	}{
+		{Point: Vec{}, Radiance: 1},
+		{Point: Vec{Y: 1}, Radiance: 4},
+		{Point: Vec{X: 3}, Radiance: 0},
+	}
+
+	for _, test := range tests {
+		sample := spot.Sample(test.Point, rng)
+		requireEqual(t, sample.Radiance.R, test.Radiance)
+	}
+}
@@ -1,45 +1,55 @@
 package raytracing
 
-import (
-	"math"
-	"math/rand"
-)
+import "math"
 
 type MaterialHit struct {
+	// Emitted is the radiance emitted by the surface itself
+	Emitted Color
+
+	// specular scattering into the single direction Scattered
 	Attenuation Color
-	Scattered   Ray
+	Scattered   *Ray
+
+	// non-specular scattering: BSDF returns the BSDF times the cosine for
+	// light arriving from direction, PDF is the density directions are
+	// sampled with. Lights are sampled explicitly at non-specular hits.
+	BSDF func(direction Vec) Color
+	PDF  PDF
 }
 
-type Material func(ray Ray, hit Hit) *MaterialHit
+type Material func(ray Ray, hit Hit, sampler Sampler) *MaterialHit
 
 func Lambertian(albedo Color) Material {
-	return func(ray Ray, hit Hit) *MaterialHit {
-		scatterDirection := hit.Normal.Add(randomUnitVector())
+	return TexturedLambertian(SolidColor(albedo))
+}
 
-		// catch zero scatter direction
-		if scatterDirection.Length() < 1e-8 {
-			scatterDirection = hit.Normal
-		}
+func TexturedLambertian(albedo Texture) Material {
+	return func(ray Ray, hit Hit, sampler Sampler) *MaterialHit {
+		color := albedo.Value(hit.U, hit.V, hit.Point)
 
 		return &MaterialHit{
-			Scattered: Ray{
-				Origin:    hit.Point,
-				Direction: scatterDirection,
+			BSDF: func(direction Vec) Color {
+				cosine := hit.Normal.Dot(direction.Normalized())
+				return color.Multiply(math.Max(cosine, 0) / math.Pi)
 			},
-			Attenuation: albedo,
+			PDF: NewCosinePDF(hit.Normal),
 		}
 	}
 }
 
 func Metal(albedo Color, fuzz float64) Material {
-	return func(ray Ray, hit Hit) *MaterialHit {
+	return TexturedMetal(SolidColor(albedo), fuzz)
+}
+
+func TexturedMetal(albedo Texture, fuzz float64) Material {
+	return func(ray Ray, hit Hit, sampler Sampler) *MaterialHit {
 		reflected := ray.Direction.Normalized().Reflect(hit.Normal)
-		scattered := Ray{hit.Point, reflected.Add(randomUnitVector().Multiply(fuzz))}
+		scattered := Ray{hit.Point, reflected.Add(randomUnitVector(sampler).Multiply(fuzz)), ray.Time, sampler}
 
 		if reflected.Dot(hit.Normal) > 0 {
 			return &MaterialHit{
-				Scattered:   scattered,
-				Attenuation: albedo,
+				Scattered:   &scattered,
+				Attenuation: albedo.Value(hit.U, hit.V, hit.Point),
 			}
 		}
 
@@ -55,7 +65,7 @@ func schlickReflectance(cosTheta, refractionRatio float64) float64 {
 }
 
 func Dielectric(indexOfRefraction float64) Material {
-	return func(ray Ray, hit Hit) *MaterialHit {
+	return func(ray Ray, hit Hit, sampler Sampler) *MaterialHit {
 		refractionRatio := indexOfRefraction
 		if hit.FrontFace {
 			refractionRatio = 1 / indexOfRefraction
@@ -66,7 +76,7 @@ func Dielectric(indexOfRefraction float64) Material {
 		sinTheta := math.Sqrt(1 - cosTheta*cosTheta)
 
 		cannotRefract := refractionRatio*sinTheta > 1
-		schlickReflect := schlickReflectance(cosTheta, refractionRatio) > rand.Float64()
+		schlickReflect := schlickReflectance(cosTheta, refractionRatio) > sampler.Float64()
 
 		var scatterDirection Vec
 		if cannotRefract || schlickReflect {
@@ -75,11 +85,24 @@ func Dielectric(indexOfRefraction float64) Material {
 			scatterDirection = normalizedDirection.Refract(hit.Normal, refractionRatio)
 		}
 
-		scattered := Ray{hit.Point, scatterDirection}
+		scattered := Ray{hit.Point, scatterDirection, ray.Time, sampler}
 
 		return &MaterialHit{
-			Scattered:   scattered,
+			Scattered:   &scattered,
 			Attenuation: Color{1, 1, 1},
 		}
 	}
 }
+
+// DiffuseLight emits light evenly from both sides of a surface
+func DiffuseLight(color Color, intensity float64) Material {
+	return TexturedDiffuseLight(SolidColor(color), intensity)
+}
+
+func TexturedDiffuseLight(color Texture, intensity float64) Material {
+	return func(ray Ray, hit Hit, sampler Sampler) *MaterialHit {
+		return &MaterialHit{
+			Emitted: color.Value(hit.U, hit.V, hit.Point).Multiply(intensity),
+		}
+	}
+}
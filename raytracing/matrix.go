@@ -0,0 +1,179 @@
+package raytracing
+
+import "math"
+
+// Matrix is a row-major 4x4 matrix for affine transforms of column vectors,
+// so that a.Multiply(b) applies b first
+type Matrix [4][4]float64
+
+var Identity = Matrix{
+	{1, 0, 0, 0},
+	{0, 1, 0, 0},
+	{0, 0, 1, 0},
+	{0, 0, 0, 1},
+}
+
+func Translate(offset Vec) Matrix {
+	return Matrix{
+		{1, 0, 0, offset.X},
+		{0, 1, 0, offset.Y},
+		{0, 0, 1, offset.Z},
+		{0, 0, 0, 1},
+	}
+}
+
+func Scale(factor Vec) Matrix {
+	return Matrix{
+		{factor.X, 0, 0, 0},
+		{0, factor.Y, 0, 0},
+		{0, 0, factor.Z, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+func RotateX(angle float64) Matrix {
+	sin, cos := math.Sincos(angle)
+	return Matrix{
+		{1, 0, 0, 0},
+		{0, cos, -sin, 0},
+		{0, sin, cos, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+func RotateY(angle float64) Matrix {
+	sin, cos := math.Sincos(angle)
+	return Matrix{
+		{cos, 0, sin, 0},
+		{0, 1, 0, 0},
+		{-sin, 0, cos, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+func RotateZ(angle float64) Matrix {
+	sin, cos := math.Sincos(angle)
+	return Matrix{
+		{cos, -sin, 0, 0},
+		{sin, cos, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Rotate rotates counter-clockwise around an arbitrary axis
+func Rotate(axis Vec, angle float64) Matrix {
+	a := axis.Normalized()
+	sin, cos := math.Sincos(angle)
+	t := 1 - cos
+
+	return Matrix{
+		{t*a.X*a.X + cos, t*a.X*a.Y - sin*a.Z, t*a.X*a.Z + sin*a.Y, 0},
+		{t*a.X*a.Y + sin*a.Z, t*a.Y*a.Y + cos, t*a.Y*a.Z - sin*a.X, 0},
+		{t*a.X*a.Z - sin*a.Y, t*a.Y*a.Z + sin*a.X, t*a.Z*a.Z + cos, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+func (a Matrix) Multiply(b Matrix) Matrix {
+	var m Matrix
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			for k := 0; k < 4; k++ {
+				m[row][col] += a[row][k] * b[k][col]
+			}
+		}
+	}
+	return m
+}
+
+func (m Matrix) Transpose() Matrix {
+	var t Matrix
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			t[col][row] = m[row][col]
+		}
+	}
+	return t
+}
+
+// Inverse uses Gauss-Jordan elimination with partial pivoting, returning
+// false for singular matrices
+func (m Matrix) Inverse() (Matrix, bool) {
+	a := m
+	inv := Identity
+
+	for col := 0; col < 4; col++ {
+		pivot := col
+		for row := col + 1; row < 4; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return Matrix{}, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+
+		scale := 1 / a[col][col]
+		for k := 0; k < 4; k++ {
+			a[col][k] *= scale
+			inv[col][k] *= scale
+		}
+
+		for row := 0; row < 4; row++ {
+			if row == col {
+				continue
+			}
+			factor := a[row][col]
+			for k := 0; k < 4; k++ {
+				a[row][k] -= factor * a[col][k]
+				inv[row][k] -= factor * inv[col][k]
+			}
+		}
+	}
+
+	return inv, true
+}
+
+func (m Matrix) TransformPoint(p Vec) Vec {
+	return Vec{
+		X: m[0][0]*p.X + m[0][1]*p.Y + m[0][2]*p.Z + m[0][3],
+		Y: m[1][0]*p.X + m[1][1]*p.Y + m[1][2]*p.Z + m[1][3],
+		Z: m[2][0]*p.X + m[2][1]*p.Y + m[2][2]*p.Z + m[2][3],
+	}
+}
+
+// TransformVector ignores the translation part
+func (m Matrix) TransformVector(v Vec) Vec {
+	return Vec{
+		X: m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z,
+		Y: m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z,
+		Z: m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z,
+	}
+}
+
+func (m Matrix) TransformAABB(box AABB) AABB {
+	if box.Min.X > box.Max.X {
+		return box
+	}
+
+	transformed := emptyAABB
+	for i := 0; i < 8; i++ {
+		corner := box.Min
+		if i&1 != 0 {
+			corner.X = box.Max.X
+		}
+		if i&2 != 0 {
+			corner.Y = box.Max.Y
+		}
+		if i&4 != 0 {
+			corner.Z = box.Max.Z
+		}
+		p := m.TransformPoint(corner)
+		transformed = transformed.Union(AABB{p, p})
+	}
+
+	return transformed
+}
@@ -0,0 +1,71 @@
+package raytracing_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func TestMatrixInverse(t *testing.T) {
+	m := raytracing.Translate(Vec{X: 1, Y: 2, Z: 3}).
+		Multiply(raytracing.Rotate(Vec{X: 1, Y: 1}, 0.7)).
+		Multiply(raytracing.Scale(Vec{X: 2, Y: -1, Z: 0.5}))
+
+	inverse, ok := m.Inverse()
+	if !ok {
+		t.Fatal("expected invertible matrix")
+	}
+
+	p := Vec{X: -4, Y: 0.5, Z: 7}
+	requireEqual(t, inverse.TransformPoint(m.TransformPoint(p)), p)
+	requireEqual(t, m.Multiply(inverse).TransformPoint(p), p)
+
+	if _, ok := raytracing.Scale(Vec{X: 1, Y: 0, Z: 1}).Inverse(); ok {
+		t.Error("expected singular matrix")
+	}
+}
+
+func TestMatrixRotate(t *testing.T) {
+	tests := []struct {
+		Matrix   raytracing.Matrix
+		Expected Vec
+	}{
+		{Matrix: raytracing.RotateX(math.Pi / 2), Expected: Vec{X: 1, Y: -3, Z: 2}},
+		{Matrix: raytracing.RotateY(math.Pi / 2), Expected: Vec{X: 3, Y: 2, Z: -1}},
+		{Matrix: raytracing.RotateZ(math.Pi / 2), Expected: Vec{X: -2, Y: 1, Z: 3}},
+		{Matrix: raytracing.Rotate(Vec{Y: 2}, math.Pi/2), Expected: Vec{X: 3, Y: 2, Z: -1}},
+	}
+
+	for _, test := range tests {
+		requireEqual(t, test.Matrix.TransformVector(Vec{X: 1, Y: 2, Z: 3}), test.Expected)
+	}
+}
+
+func TestTransformed(t *testing.T) {
+	sphere := raytracing.Sphere{Radius: 1}
+	transformed, err := raytracing.NewTransformed(sphere,
+		raytracing.Translate(Vec{X: 5}).Multiply(raytracing.Scale(Vec{X: 2, Y: 1, Z: 1})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hit := transformed.Hit(raytracing.Ray{Origin: Vec{X: 10}, Direction: Vec{X: -1}}, 0.001, 10000)
+	if hit == nil {
+		t.Fatal("expected hit")
+	}
+	requireEqual(t, hit.T, 3)
+	requireEqual(t, hit.Point, Vec{X: 7})
+	requireEqual(t, hit.Normal, Vec{X: 1})
+
+	box := transformed.BoundingBox()
+	requireEqual(t, box.Min, Vec{X: 3, Y: -1, Z: -1})
+	requireEqual(t, box.Max, Vec{X: 7, Y: 1, Z: 1})
+}
+
+func TestTransformedSingular(t *testing.T) {
+	sphere := raytracing.Sphere{Radius: 1}
+	if _, err := raytracing.NewTransformed(sphere, raytracing.Scale(Vec{X: 1, Z: 1})); err == nil {
+		t.Error("expected error for a transform scaling an axis to zero")
+	}
+}
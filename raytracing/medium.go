@@ -0,0 +1,85 @@
+package raytracing
+
+import "math"
+
+// ConstantMedium is a homogeneous volume like fog or smoke filling a closed
+// boundary. Rays passing through it scatter after an exponentially
+// distributed distance depending on the density.
+type ConstantMedium struct {
+	Boundary      Hittable
+	Density       float64
+	PhaseFunction Material
+}
+
+func NewConstantMedium(boundary Hittable, density float64, albedo Color) ConstantMedium {
+	return ConstantMedium{
+		Boundary:      boundary,
+		Density:       density,
+		PhaseFunction: Isotropic(albedo),
+	}
+}
+
+// Isotropic scatters uniformly in all directions
+func Isotropic(albedo Color) Material {
+	phase := albedo.Multiply(1 / (4 * math.Pi))
+
+	return func(ray Ray, hit Hit, sampler Sampler) *MaterialHit {
+		return &MaterialHit{
+			BSDF: func(direction Vec) Color {
+				return phase
+			},
+			PDF: UniformSpherePDF{},
+		}
+	}
+}
+
+func (m ConstantMedium) Hit(ray Ray, tMin, tMax float64) *Hit {
+	if m.Density <= 0 {
+		return nil
+	}
+
+	// entry and exit of the boundary along the whole line, the ray origin
+	// may lie inside the volume
+	entry := m.Boundary.Hit(ray, math.Inf(-1), math.Inf(1))
+	if entry == nil {
+		return nil
+	}
+	exit := m.Boundary.Hit(ray, entry.T+0.0001, math.Inf(1))
+	if exit == nil {
+		return nil
+	}
+
+	t0 := math.Max(entry.T, tMin)
+	t1 := math.Min(exit.T, tMax)
+	if t0 >= t1 {
+		return nil
+	}
+	t0 = math.Max(t0, 0)
+
+	rayLength := ray.Direction.Length()
+	distanceInside := (t1 - t0) * rayLength
+	// rays without sampler, e.g. from tests, always scatter at the same
+	// distance
+	sampler := ray.Sampler
+	if sampler == nil {
+		sampler = NewRNG(0)
+	}
+	hitDistance := -math.Log(1-sampler.Float64()) / m.Density
+	if hitDistance > distanceInside {
+		return nil
+	}
+
+	t := t0 + hitDistance/rayLength
+
+	return &Hit{
+		Point:     ray.At(t),
+		T:         t,
+		Normal:    Vec{X: 1}, // arbitrary, phase functions don't use it
+		Material:  m.PhaseFunction,
+		FrontFace: true,
+	}
+}
+
+func (m ConstantMedium) BoundingBox() AABB {
+	return m.Boundary.BoundingBox()
+}
@@ -0,0 +1,66 @@
+package raytracing_test
+
+import (
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func TestConstantMedium(t *testing.T) {
+	boundary := raytracing.Sphere{Radius: 1}
+	ray := raytracing.Ray{Origin: Vec{Z: 5}, Direction: Vec{Z: -1}}
+
+	// a very dense medium scatters right at the boundary
+	dense := raytracing.NewConstantMedium(boundary, 1e9, raytracing.Color{R: 1, G: 1, B: 1})
+	hit := dense.Hit(ray, 0.001, 10000)
+	if hit == nil {
+		t.Fatal("expected hit in dense medium")
+	}
+	requireEqual(t, hit.T, 4)
+
+	// from inside, the scattering starts at the ray origin
+	inside := raytracing.Ray{Origin: Vec{}, Direction: Vec{Z: -1}}
+	hit = dense.Hit(inside, 0.001, 10000)
+	if hit == nil || hit.T > 0.01 {
+		t.Fatalf("expected hit close to the origin, got %#v", hit)
+	}
+
+	// a very thin medium is almost never hit
+	thin := raytracing.NewConstantMedium(boundary, 1e-12, raytracing.Color{R: 1, G: 1, B: 1})
+	if hit := thin.Hit(ray, 0.001, 10000); hit != nil {
+		t.Errorf("expected no hit in thin medium, got %#v", hit)
+	}
+
+	// objects in front of the volume end the ray first
+	if hit := dense.Hit(ray, 0.001, 3); hit != nil {
+		t.Errorf("expected no hit beyond tMax, got %#v", hit)
+	}
+}
+
+func TestConstantMediumSampler(t *testing.T) {
+	boundary := raytracing.Sphere{Radius: 1}
+	medium := raytracing.NewConstantMedium(boundary, 0.5, raytracing.White)
+
+	distance := func(sampler raytracing.Sampler) float64 {
+		ray := raytracing.Ray{Origin: Vec{Z: 5}, Direction: Vec{Z: -1}, Sampler: sampler}
+		hit := medium.Hit(ray, 0.001, 10000)
+		if hit == nil {
+			return 0
+		}
+		return hit.T
+	}
+
+	// the same ray scatters at distances drawn from its sampler
+	sampler := raytracing.NewRNG(1)
+	distances := make(map[float64]bool)
+	for i := 0; i < 20; i++ {
+		distances[distance(sampler)] = true
+	}
+	if len(distances) < 5 {
+		t.Errorf("expected varying scattering distances, got %v", distances)
+	}
+
+	if distance(raytracing.NewRNG(7)) != distance(raytracing.NewRNG(7)) {
+		t.Error("expected samplers with the same seed to scatter at the same distance")
+	}
+}
@@ -0,0 +1,123 @@
+package raytracing
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Mesh is an indexed triangle mesh; all of its triangles share the vertex
+// buffers. Use NewMesh to build one, its geometry can't be changed
+// afterwards.
+type Mesh struct {
+	Material Material
+
+	vertices  []Vec
+	normals   []Vec
+	texCoords [][2]float64
+	indices   []int
+
+	bvh Hittable
+}
+
+// NewMesh builds a mesh from three vertex indices per triangle. Normals
+// for smooth shading and texture coordinates are optional, if given they
+// are indexed like vertices.
+func NewMesh(vertices, normals []Vec, texCoords [][2]float64, indices []int, material Material) (*Mesh, error) {
+	if len(indices) == 0 {
+		return nil, errors.New("mesh without triangles")
+	}
+	if len(indices)%3 != 0 {
+		return nil, fmt.Errorf("%d indices don't form triangles", len(indices))
+	}
+	if len(normals) > 0 && len(normals) != len(vertices) {
+		return nil, fmt.Errorf("%d normals for %d vertices", len(normals), len(vertices))
+	}
+	if len(texCoords) > 0 && len(texCoords) != len(vertices) {
+		return nil, fmt.Errorf("%d texture coordinates for %d vertices", len(texCoords), len(vertices))
+	}
+	for _, index := range indices {
+		if index < 0 || index >= len(vertices) {
+			return nil, fmt.Errorf("vertex index %d out of range [0, %d)", index, len(vertices))
+		}
+	}
+
+	mesh := &Mesh{
+		Material:  material,
+		vertices:  vertices,
+		normals:   normals,
+		texCoords: texCoords,
+		indices:   indices,
+	}
+	mesh.bvh = NewBVH(mesh.Triangles())
+
+	return mesh, nil
+}
+
+// Triangles returns the faces of the mesh as individual hittables, e.g. to
+// put them into a BVH together with other objects
+func (m *Mesh) Triangles() []Hittable {
+	triangles := make([]Hittable, len(m.indices)/3)
+	for i := range triangles {
+		triangles[i] = meshTriangle{mesh: m, index: i * 3}
+	}
+	return triangles
+}
+
+func (m *Mesh) Hit(ray Ray, tMin, tMax float64) *Hit {
+	// a zero mesh has no triangles
+	if m.bvh == nil {
+		return nil
+	}
+	return m.bvh.Hit(ray, tMin, tMax)
+}
+
+func (m *Mesh) BoundingBox() AABB {
+	if m.bvh == nil {
+		return AABB{}
+	}
+	return m.bvh.BoundingBox()
+}
+
+type meshTriangle struct {
+	mesh  *Mesh
+	index int
+}
+
+func (tr meshTriangle) vertices() (a, b, c Vec) {
+	indices := tr.mesh.indices[tr.index : tr.index+3]
+	return tr.mesh.vertices[indices[0]], tr.mesh.vertices[indices[1]], tr.mesh.vertices[indices[2]]
+}
+
+func (tr meshTriangle) Hit(ray Ray, tMin, tMax float64) *Hit {
+	a, b, c := tr.vertices()
+	t, u, v, ok := intersectTriangle(a, b, c, ray, tMin, tMax)
+	if !ok {
+		return nil
+	}
+
+	geometricNormal := b.Subtract(a).Cross(c.Subtract(a)).Normalized()
+	shadingNormal := geometricNormal
+	indices := tr.mesh.indices[tr.index : tr.index+3]
+
+	// interpolate vertex normals for smooth shading
+	if len(tr.mesh.normals) > 0 {
+		shadingNormal = tr.mesh.normals[indices[0]].Multiply(1 - u - v).
+			Add(tr.mesh.normals[indices[1]].Multiply(u)).
+			Add(tr.mesh.normals[indices[2]].Multiply(v)).
+			Normalized()
+	}
+
+	texU, texV := u, v
+	if len(tr.mesh.texCoords) > 0 {
+		t0, t1, t2 := tr.mesh.texCoords[indices[0]], tr.mesh.texCoords[indices[1]], tr.mesh.texCoords[indices[2]]
+		texU = (1-u-v)*t0[0] + u*t1[0] + v*t2[0]
+		texV = (1-u-v)*t0[1] + u*t1[1] + v*t2[1]
+	}
+
+	return triangleHit(ray, t, geometricNormal, shadingNormal, texU, texV, tr.mesh.Material)
+}
+
+func (tr meshTriangle) BoundingBox() AABB {
+	a, b, c := tr.vertices()
+	return Triangle{A: a, B: b, C: c}.BoundingBox()
+}
@@ -0,0 +1,220 @@
+package raytracing
+
+import "math"
+
+// PrincipledOptions follow the glTF metallic-roughness model
+type PrincipledOptions struct {
+	BaseColor Color
+	// Metallic blends between a dielectric (0) and a conductor (1)
+	Metallic float64
+	// Roughness is perceptual, the GGX alpha is its square
+	Roughness float64
+	// IOR of the dielectric specular layer, zero means 1.5
+	IOR float64
+
+	// Clearcoat is the strength of an additional glossy layer with IOR 1.5
+	Clearcoat          float64
+	ClearcoatRoughness float64
+}
+
+// Principled is a physically based material with a Lambertian base and a
+// GGX microfacet specular layer, optionally topped by a clearcoat
+func Principled(options PrincipledOptions) Material {
+	alpha := roughnessToAlpha(options.Roughness)
+	clearcoatAlpha := roughnessToAlpha(options.ClearcoatRoughness)
+	ior := options.IOR
+	if ior == 0 {
+		ior = 1.5
+	}
+
+	base := options.BaseColor
+	metallic := math.Max(0, math.Min(1, options.Metallic))
+	clearcoat := math.Max(0, math.Min(1, options.Clearcoat))
+
+	// the share of samples for each lobe
+	diffuseWeight := 0.5 * (1 - metallic)
+	clearcoatWeight := 0.25 * clearcoat
+
+	return func(ray Ray, hit Hit, sampler Sampler) *MaterialHit {
+		normal := hit.Normal
+		outgoing := ray.Direction.Normalized().Multiply(-1)
+		basis := NewONB(normal)
+
+		var pdf PDF = ggxPDF{basis, outgoing, alpha}
+		if diffuseWeight > 0 {
+			pdf = MixturePDF{A: CosinePDF{basis}, B: pdf, Weight: diffuseWeight}
+		}
+		if clearcoatWeight > 0 {
+			pdf = MixturePDF{A: ggxPDF{basis, outgoing, clearcoatAlpha}, B: pdf, Weight: clearcoatWeight}
+		}
+
+		return &MaterialHit{
+			BSDF: func(direction Vec) Color {
+				incoming := direction.Normalized()
+				cosIn := normal.Dot(incoming)
+				if cosIn <= 0 {
+					return Black
+				}
+				half := outgoing.Add(incoming).Normalized()
+				cosHalf := outgoing.Dot(half)
+
+				specular := ggxSpecular(normal, outgoing, incoming, alpha)
+				fresnel := fresnelDielectric(cosHalf, ior)
+
+				dielectric := base.Multiply(cosIn / math.Pi * (1 - fresnel)).
+					Add(White.Multiply(specular * fresnel))
+				metal := schlickFresnel(cosHalf, base).Multiply(specular)
+				value := dielectric.Multiply(1 - metallic).Add(metal.Multiply(metallic))
+
+				if clearcoat > 0 {
+					coat := clearcoat * fresnelDielectric(cosHalf, 1.5)
+					value = value.Multiply(1 - coat).
+						Add(White.Multiply(coat * ggxSpecular(normal, outgoing, incoming, clearcoatAlpha)))
+				}
+
+				return value
+			},
+			PDF: pdf,
+		}
+	}
+}
+
+func roughnessToAlpha(roughness float64) float64 {
+	// very small alphas are numerically unstable, so near mirror surfaces are
+	// clamped to a slightly rough lobe
+	return math.Max(roughness*roughness, 1e-3)
+}
+
+// ggxD is the Trowbridge-Reitz normal distribution
+func ggxD(cosHalf, alpha float64) float64 {
+	a2 := alpha * alpha
+	d := cosHalf*cosHalf*(a2-1) + 1
+	return a2 / (math.Pi * d * d)
+}
+
+// smithG1 is the Smith masking function for GGX
+func smithG1(cosine, alpha float64) float64 {
+	a2 := alpha * alpha
+	return 2 * cosine / (cosine + math.Sqrt(a2+(1-a2)*cosine*cosine))
+}
+
+// ggxSpecular returns D·G / (4 cosOut cosIn) times cosIn, without Fresnel
+func ggxSpecular(normal, outgoing, incoming Vec, alpha float64) float64 {
+	cosOut := normal.Dot(outgoing)
+	cosIn := normal.Dot(incoming)
+	if cosOut <= 0 || cosIn <= 0 {
+		return 0
+	}
+	half := outgoing.Add(incoming).Normalized()
+
+	return ggxD(normal.Dot(half), alpha) * smithG1(cosOut, alpha) * smithG1(cosIn, alpha) / (4 * cosOut)
+}
+
+// fresnelDielectric is the unpolarized reflectance of light arriving from
+// outside a dielectric with relative index of refraction eta
+func fresnelDielectric(cosIn, eta float64) float64 {
+	cosIn = math.Max(0, math.Min(1, cosIn))
+	sin2Out := (1 - cosIn*cosIn) / (eta * eta)
+	if sin2Out >= 1 {
+		return 1
+	}
+	cosOut := math.Sqrt(1 - sin2Out)
+
+	parallel := (eta*cosIn - cosOut) / (eta*cosIn + cosOut)
+	perpendicular := (cosIn - eta*cosOut) / (cosIn + eta*cosOut)
+	return (parallel*parallel + perpendicular*perpendicular) / 2
+}
+
+// schlickFresnel approximates the reflectance of a conductor with normal
+// incidence reflectance f0
+func schlickFresnel(cosIn float64, f0 Color) Color {
+	weight := math.Pow(1-math.Max(0, math.Min(1, cosIn)), 5)
+	return f0.Multiply(1 - weight).Add(White.Multiply(weight))
+}
+
+// ggxPDF samples reflected directions from the distribution of normals
+type ggxPDF struct {
+	basis    ONB
+	outgoing Vec
+	alpha    float64
+}
+
+func (p ggxPDF) Value(direction Vec) float64 {
+	incoming := direction.Normalized()
+	if incoming.Dot(p.basis.W) <= 0 {
+		return 0
+	}
+	half := p.outgoing.Add(incoming).Normalized()
+	cosHalf := half.Dot(p.basis.W)
+	cosOutHalf := p.outgoing.Dot(half)
+	if cosHalf <= 0 || cosOutHalf <= 0 {
+		return 0
+	}
+
+	return ggxD(cosHalf, p.alpha) * cosHalf / (4 * cosOutHalf)
+}
+
+func (p ggxPDF) Generate(sampler Sampler) Vec {
+	r1 := sampler.Float64()
+	r2 := sampler.Float64()
+
+	tan2Theta := p.alpha * p.alpha * r1 / (1 - r1)
+	cosTheta := 1 / math.Sqrt(1+tan2Theta)
+	sinTheta := math.Sqrt(math.Max(0, 1-cosTheta*cosTheta))
+	phi := 2 * math.Pi * r2
+
+	half := p.basis.Local(Vec{X: math.Cos(phi) * sinTheta, Y: math.Sin(phi) * sinTheta, Z: cosTheta})
+	return p.outgoing.Multiply(-1).Reflect(half)
+}
+
+// Conductor is a rough metal described by its complex index of refraction
+// eta + ik per color channel
+func Conductor(eta, k Color, roughness float64) Material {
+	alpha := roughnessToAlpha(roughness)
+
+	return func(ray Ray, hit Hit, sampler Sampler) *MaterialHit {
+		normal := hit.Normal
+		outgoing := ray.Direction.Normalized().Multiply(-1)
+
+		return &MaterialHit{
+			BSDF: func(direction Vec) Color {
+				incoming := direction.Normalized()
+				specular := ggxSpecular(normal, outgoing, incoming, alpha)
+				if specular == 0 {
+					return Black
+				}
+				cosHalf := outgoing.Dot(outgoing.Add(incoming).Normalized())
+
+				return Color{
+					R: fresnelConductor(cosHalf, eta.R, k.R),
+					G: fresnelConductor(cosHalf, eta.G, k.G),
+					B: fresnelConductor(cosHalf, eta.B, k.B),
+				}.Multiply(specular)
+			},
+			PDF: ggxPDF{NewONB(normal), outgoing, alpha},
+		}
+	}
+}
+
+// fresnelConductor is the unpolarized reflectance of a conductor with
+// complex index of refraction eta + ik
+func fresnelConductor(cosIn, eta, k float64) float64 {
+	cosIn = math.Max(0, math.Min(1, cosIn))
+	cos2 := cosIn * cosIn
+	sin2 := 1 - cos2
+	eta2, k2 := eta*eta, k*k
+
+	t0 := eta2 - k2 - sin2
+	a2b2 := math.Sqrt(t0*t0 + 4*eta2*k2)
+	a := math.Sqrt(math.Max(0, (a2b2+t0)/2))
+
+	t1 := a2b2 + cos2
+	t2 := 2 * cosIn * a
+	perpendicular := (t1 - t2) / (t1 + t2)
+
+	t3 := cos2*a2b2 + sin2*sin2
+	t4 := t2 * sin2
+	parallel := perpendicular * (t3 - t4) / (t3 + t4)
+
+	return (parallel + perpendicular) / 2
+}
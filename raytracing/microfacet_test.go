@@ -0,0 +1,87 @@
+package raytracing_test
+
+import (
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func TestPrincipledEnergyConservation(t *testing.T) {
+	rng := raytracing.NewRNG(1)
+	options := []raytracing.PrincipledOptions{
+		{BaseColor: raytracing.White, Roughness: 0.5},
+		{BaseColor: raytracing.White, Roughness: 0.1, Metallic: 1},
+		{BaseColor: raytracing.White, Roughness: 0.8, Metallic: 1},
+		{BaseColor: raytracing.White, Roughness: 0.3, Metallic: 0.5, Clearcoat: 1, ClearcoatRoughness: 0.1},
+	}
+	ray := raytracing.Ray{Direction: Vec{X: 1, Y: -1}}
+	hit := raytracing.Hit{Normal: Vec{Y: 1}, FrontFace: true}
+
+	for _, o := range options {
+		materialHit := raytracing.Principled(o)(ray, hit, rng)
+
+		// the directional albedo of a white surface can't exceed one
+		const samples = 100000
+		albedo := 0.0
+		for i := 0; i < samples; i++ {
+			direction := materialHit.PDF.Generate(rng)
+			pdf := materialHit.PDF.Value(direction)
+			if pdf > 0 {
+				albedo += materialHit.BSDF(direction).G / pdf
+			}
+		}
+		albedo /= samples
+
+		if albedo > 1.02 || albedo < 0.5 {
+			t.Errorf("%+v: expected albedo in [0.5, 1], got %v", o, albedo)
+		}
+	}
+}
+
+func TestPrincipledNoLightBelowSurface(t *testing.T) {
+	material := raytracing.Principled(raytracing.PrincipledOptions{BaseColor: raytracing.White, Roughness: 0.5})
+	materialHit := material(raytracing.Ray{Direction: Vec{Y: -1}}, raytracing.Hit{Normal: Vec{Y: 1}}, raytracing.NewRNG(1))
+
+	below := Vec{X: 1, Y: -1}
+	if value := materialHit.BSDF(below); value != raytracing.Black {
+		t.Errorf("expected no scattering below the surface, got %v", value)
+	}
+	if pdf := materialHit.PDF.Value(below); pdf != 0 {
+		t.Errorf("expected zero density below the surface, got %v", pdf)
+	}
+}
+
+func TestConductorPresets(t *testing.T) {
+	ray := raytracing.Ray{Direction: Vec{Y: -1}}
+	hit := raytracing.Hit{Normal: Vec{Y: 1}, FrontFace: true}
+
+	for _, name := range []string{"gold", "silver", "copper", "aluminium", "chrome"} {
+		material, err := raytracing.Preset(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		materialHit := material(ray, hit, raytracing.NewRNG(1))
+
+		// polished metals reflect most of the light at normal incidence
+		value := materialHit.BSDF(Vec{Y: 1}).Multiply(1 / materialHit.PDF.Value(Vec{Y: 1}))
+		for _, channel := range []float64{value.R, value.G, value.B} {
+			if channel < 0.3 || channel > 1 {
+				t.Errorf("%s: expected reflectance in [0.3, 1], got %v", name, value)
+				break
+			}
+		}
+	}
+
+	material, _ := raytracing.Preset("gold")
+	gold := material(ray, hit, raytracing.NewRNG(1)).BSDF(Vec{Y: 1})
+	if gold.B >= gold.R {
+		t.Errorf("expected gold to reflect more red than blue, got %v", gold)
+	}
+}
+
+func TestPresetUnknown(t *testing.T) {
+	material, err := raytracing.Preset("golld")
+	if err == nil || material != nil {
+		t.Errorf("expected error for unknown preset, got %v", err)
+	}
+}
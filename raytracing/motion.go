@@ -0,0 +1,148 @@
+package raytracing
+
+import "math"
+
+// MovingSphere moves linearly from Center0 at Time0 to Center1 at Time1
+type MovingSphere struct {
+	Center0  Vec
+	Center1  Vec
+	Time0    float64
+	Time1    float64
+	Radius   float64
+	Material Material
+}
+
+// progress maps time to [0, 1] within the interval [time0, time1]
+func progress(time, time0, time1 float64) float64 {
+	if time1 == time0 {
+		return 0
+	}
+	return math.Max(0, math.Min(1, (time-time0)/(time1-time0)))
+}
+
+func (s MovingSphere) center(time float64) Vec {
+	t := progress(time, s.Time0, s.Time1)
+	return s.Center0.Add(s.Center1.Subtract(s.Center0).Multiply(t))
+}
+
+func (s MovingSphere) Hit(ray Ray, tMin, tMax float64) *Hit {
+	sphere := Sphere{
+		Center:   s.center(ray.Time),
+		Radius:   s.Radius,
+		Material: s.Material,
+	}
+	return sphere.Hit(ray, tMin, tMax)
+}
+
+func (s MovingSphere) BoundingBox() AABB {
+	start := Sphere{Center: s.Center0, Radius: s.Radius}
+	end := Sphere{Center: s.Center1, Radius: s.Radius}
+	return start.BoundingBox().Union(end.BoundingBox())
+}
+
+// Keyframe is a decomposed affine transform that can be interpolated
+type Keyframe struct {
+	Translation Vec
+	// Rotation holds euler angles in radians, applied in X, Y, Z order
+	Rotation Vec
+	// Scale is applied first, a zero vector means no scaling
+	Scale Vec
+}
+
+func (k Keyframe) scale() Vec {
+	if k.Scale == (Vec{}) {
+		return Vec{X: 1, Y: 1, Z: 1}
+	}
+	return k.Scale
+}
+
+func (k Keyframe) Matrix() Matrix {
+	return Translate(k.Translation).
+		Multiply(RotateZ(k.Rotation.Z)).
+		Multiply(RotateY(k.Rotation.Y)).
+		Multiply(RotateX(k.Rotation.X)).
+		Multiply(Scale(k.scale()))
+}
+
+func (k Keyframe) inverse() Matrix {
+	s := k.scale()
+	return Scale(Vec{X: 1 / s.X, Y: 1 / s.Y, Z: 1 / s.Z}).
+		Multiply(RotateX(-k.Rotation.X)).
+		Multiply(RotateY(-k.Rotation.Y)).
+		Multiply(RotateZ(-k.Rotation.Z)).
+		Multiply(Translate(k.Translation.Multiply(-1)))
+}
+
+func (k Keyframe) lerp(other Keyframe, t float64) Keyframe {
+	mix := func(a, b Vec) Vec {
+		return a.Add(b.Subtract(a).Multiply(t))
+	}
+	return Keyframe{
+		Translation: mix(k.Translation, other.Translation),
+		Rotation:    mix(k.Rotation, other.Rotation),
+		Scale:       mix(k.scale(), other.scale()),
+	}
+}
+
+// Animated is a time-varying Transformed, interpolating between two
+// keyframes over [Time0, Time1]
+type Animated struct {
+	Object Hittable
+	From   Keyframe
+	To     Keyframe
+	Time0  float64
+	Time1  float64
+
+	box AABB
+}
+
+// animatedBoundsSteps is the number of time samples used to bound the
+// swept volume of an animated object
+const animatedBoundsSteps = 32
+
+func NewAnimated(object Hittable, from, to Keyframe, time0, time1 float64) *Animated {
+	a := &Animated{
+		Object: object,
+		From:   from,
+		To:     to,
+		Time0:  time0,
+		Time1:  time1,
+	}
+
+	objectBox := object.BoundingBox()
+	a.box = emptyAABB
+	for i := 0; i <= animatedBoundsSteps; i++ {
+		keyframe := from.lerp(to, float64(i)/animatedBoundsSteps)
+		a.box = a.box.Union(keyframe.Matrix().TransformAABB(objectBox))
+	}
+
+	// rotations can bulge out between samples
+	extent := a.box.Extent().Length() * 0.01
+	a.box.Min = a.box.Min.Subtract(Vec{X: extent, Y: extent, Z: extent})
+	a.box.Max = a.box.Max.Add(Vec{X: extent, Y: extent, Z: extent})
+
+	return a
+}
+
+func (a *Animated) Hit(ray Ray, tMin, tMax float64) *Hit {
+	keyframe := a.From.lerp(a.To, progress(ray.Time, a.Time0, a.Time1))
+	inverse := keyframe.inverse()
+
+	objectRay := ray
+	objectRay.Origin = inverse.TransformPoint(ray.Origin)
+	objectRay.Direction = inverse.TransformVector(ray.Direction)
+
+	hit := a.Object.Hit(objectRay, tMin, tMax)
+	if hit == nil {
+		return nil
+	}
+
+	hit.Point = keyframe.Matrix().TransformPoint(hit.Point)
+	hit.Normal = inverse.Transpose().TransformVector(hit.Normal).Normalized()
+
+	return hit
+}
+
+func (a *Animated) BoundingBox() AABB {
+	return a.box
+}
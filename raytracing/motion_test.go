@@ -0,0 +1,68 @@
+package raytracing_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func TestMovingSphere(t *testing.T) {
+	sphere := raytracing.MovingSphere{
+		Center0: Vec{X: -2},
+		Center1: Vec{X: 2},
+		Time0:   0,
+		Time1:   1,
+		Radius:  1,
+	}
+
+	tests := []struct {
+		Time float64
+		Hit  bool
+	}{
+		{Time: 0, Hit: false},
+		{Time: 0.5, Hit: true},
+		{Time: 1, Hit: false},
+	}
+
+	for _, test := range tests {
+		ray := raytracing.Ray{Origin: Vec{Z: 5}, Direction: Vec{Z: -1}, Time: test.Time}
+		if hit := sphere.Hit(ray, 0.001, 10000); (hit != nil) != test.Hit {
+			t.Errorf("time %v: expected hit %v", test.Time, test.Hit)
+		}
+	}
+
+	box := sphere.BoundingBox()
+	requireEqual(t, box.Min, Vec{X: -3, Y: -1, Z: -1})
+	requireEqual(t, box.Max, Vec{X: 3, Y: 1, Z: 1})
+}
+
+func TestAnimated(t *testing.T) {
+	// a box rotating a quarter turn around Y while moving up
+	animated := raytracing.NewAnimated(
+		raytracing.NewBox(Vec{X: -0.1, Y: -0.1, Z: 0}, Vec{X: 0.1, Y: 0.1, Z: 2}, nil),
+		raytracing.Keyframe{},
+		raytracing.Keyframe{Translation: Vec{Y: 1}, Rotation: Vec{Y: math.Pi / 2}},
+		0, 1,
+	)
+
+	start := raytracing.Ray{Origin: Vec{Z: 1, Y: 5}, Direction: Vec{Y: -1}, Time: 0}
+	if hit := animated.Hit(start, 0.001, 10000); hit == nil {
+		t.Error("expected hit at start time")
+	} else {
+		requireEqual(t, hit.T, 4.9)
+	}
+
+	end := raytracing.Ray{Origin: Vec{X: 1, Y: 5}, Direction: Vec{Y: -1}, Time: 1}
+	if hit := animated.Hit(end, 0.001, 10000); hit == nil {
+		t.Error("expected hit at end time")
+	} else {
+		requireEqual(t, hit.T, 3.9)
+		requireEqual(t, hit.Normal, Vec{Y: 1})
+	}
+
+	end.Time = 0
+	if hit := animated.Hit(end, 0.001, 10000); hit != nil {
+		t.Error("expected miss at start time")
+	}
+}
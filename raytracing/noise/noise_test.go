@@ -0,0 +1,82 @@
+package noise_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing/noise"
+)
+
+func TestPerlinDeterministic(t *testing.T) {
+	a := noise.NewPerlin(42)
+	b := noise.NewPerlin(42)
+	c := noise.NewPerlin(7)
+
+	differs := false
+	for i := 0; i < 100; i++ {
+		x, y, z := float64(i)*0.37, float64(i)*0.11, float64(i)*-0.53
+
+		if a.Noise(x, y, z) != b.Noise(x, y, z) {
+			t.Fatalf("same seed gives different noise at %v %v %v", x, y, z)
+		}
+		if a.Noise(x, y, z) != c.Noise(x, y, z) {
+			differs = true
+		}
+		if n := a.Noise(x, y, z); n < -1.5 || n > 1.5 {
+			t.Errorf("noise %v out of range", n)
+		}
+	}
+
+	if !differs {
+		t.Error("different seeds give the same noise")
+	}
+}
+
+func TestPerlinLattice(t *testing.T) {
+	// gradient noise vanishes on lattice points with both smoothings
+	for _, smoothing := range []noise.Smoothing{noise.Hermite, noise.Trilinear} {
+		p := noise.NewPerlin(1)
+		p.Smoothing = smoothing
+		if n := p.Noise(3, -2, 5); n != 0 {
+			t.Errorf("expected 0 on lattice point, got %v", n)
+		}
+	}
+}
+
+func TestPerlinTurbulence(t *testing.T) {
+	p := noise.NewPerlin(42)
+
+	for i := 0; i < 100; i++ {
+		x, y, z := float64(i)*0.37, float64(i)*0.11, float64(i)*-0.53
+
+		// every octave adds its absolute value
+		previous := 0.0
+		for depth := 1; depth <= 4; depth++ {
+			turbulence := p.Turbulence(x, y, z, depth)
+			if turbulence < previous {
+				t.Fatalf("turbulence decreased from %v to %v at depth %d", previous, turbulence, depth)
+			}
+			previous = turbulence
+		}
+		if one := p.Turbulence(x, y, z, 1); one != math.Abs(p.Noise(x, y, z)) {
+			t.Errorf("expected single octave to be the absolute noise, got %v", one)
+		}
+	}
+}
+
+func TestWorley(t *testing.T) {
+	a := noise.NewWorley(3)
+	b := noise.NewWorley(3)
+
+	for i := 0; i < 100; i++ {
+		x, y, z := float64(i)*0.37, float64(i)*-0.11, float64(i)*0.53
+
+		f1, f2 := a.Distances(x, y, z)
+		if f1 != b.Noise(x, y, z) {
+			t.Fatalf("same seed gives different noise at %v %v %v", x, y, z)
+		}
+		if f1 > f2 || f1 > math.Sqrt(3) {
+			t.Errorf("unexpected distances %v, %v", f1, f2)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Package noise provides seeded procedural noise functions. The same seed
+// always produces the same pattern.
+package noise
+
+import (
+	"math"
+	"math/rand"
+)
+
+type Smoothing int
+
+const (
+	// Hermite smooths the interpolation weights with 3t²-2t³
+	Hermite Smoothing = iota
+	// Trilinear interpolates linearly, which shows the lattice
+	Trilinear
+)
+
+const pointCount = 256
+
+type vec3 struct{ x, y, z float64 }
+
+func (a vec3) dot(x, y, z float64) float64 {
+	return a.x*x + a.y*y + a.z*z
+}
+
+// Perlin is gradient noise on an integer lattice
+type Perlin struct {
+	Smoothing Smoothing
+
+	gradients [pointCount]vec3
+	permX     [pointCount]int
+	permY     [pointCount]int
+	permZ     [pointCount]int
+}
+
+func NewPerlin(seed int64) *Perlin {
+	rng := rand.New(rand.NewSource(seed))
+	p := &Perlin{}
+
+	for i := range p.gradients {
+		// uniformly distributed unit vectors
+		z := 1 - 2*rng.Float64()
+		r := math.Sqrt(1 - z*z)
+		phi := 2 * math.Pi * rng.Float64()
+		p.gradients[i] = vec3{r * math.Cos(phi), r * math.Sin(phi), z}
+	}
+
+	for _, perm := range []*[pointCount]int{&p.permX, &p.permY, &p.permZ} {
+		for i := range perm {
+			perm[i] = i
+		}
+		rng.Shuffle(pointCount, func(i, j int) {
+			perm[i], perm[j] = perm[j], perm[i]
+		})
+	}
+
+	return p
+}
+
+// Noise returns a value in about [-1, 1]
+func (p *Perlin) Noise(x, y, z float64) float64 {
+	fx, fy, fz := math.Floor(x), math.Floor(y), math.Floor(z)
+	u, v, w := x-fx, y-fy, z-fz
+	i, j, k := int(fx), int(fy), int(fz)
+
+	var weights [2][2][2]float64
+	for di := 0; di < 2; di++ {
+		for dj := 0; dj < 2; dj++ {
+			for dk := 0; dk < 2; dk++ {
+				gradient := p.gradients[p.permX[(i+di)&255]^p.permY[(j+dj)&255]^p.permZ[(k+dk)&255]]
+				weights[di][dj][dk] = gradient.dot(u-float64(di), v-float64(dj), w-float64(dk))
+			}
+		}
+	}
+
+	if p.Smoothing == Hermite {
+		u = u * u * (3 - 2*u)
+		v = v * v * (3 - 2*v)
+		w = w * w * (3 - 2*w)
+	}
+
+	lerp := func(a, b, t float64) float64 { return a + (b-a)*t }
+
+	return lerp(
+		lerp(lerp(weights[0][0][0], weights[0][0][1], w), lerp(weights[0][1][0], weights[0][1][1], w), v),
+		lerp(lerp(weights[1][0][0], weights[1][0][1], w), lerp(weights[1][1][0], weights[1][1][1], w), v),
+		u,
+	)
+}
+
+// Turbulence sums the absolute value of depth octaves of noise, each with
+// double the frequency and half the amplitude of the previous one
+func (p *Perlin) Turbulence(x, y, z float64, depth int) float64 {
+	sum := 0.0
+	weight := 1.0
+
+	for i := 0; i < depth; i++ {
+		sum += weight * math.Abs(p.Noise(x, y, z))
+		weight *= 0.5
+		x, y, z = x*2, y*2, z*2
+	}
+
+	return sum
+}
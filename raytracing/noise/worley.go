@@ -0,0 +1,66 @@
+package noise
+
+import "math"
+
+// Worley is cellular noise: every cell of the integer lattice contains one
+// feature point, the noise is the distance to the nearest ones
+type Worley struct {
+	seed uint64
+}
+
+func NewWorley(seed int64) *Worley {
+	return &Worley{seed: uint64(seed)}
+}
+
+// splitmix64 finalizer
+func mix(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+func (w *Worley) featurePoint(i, j, k int) vec3 {
+	h := mix(w.seed ^ mix(uint64(int64(i))^mix(uint64(int64(j))^mix(uint64(int64(k))))))
+
+	// three 21 bit fractions from one hash
+	const mask = 1<<21 - 1
+	return vec3{
+		float64(i) + float64(h&mask)/mask,
+		float64(j) + float64((h>>21)&mask)/mask,
+		float64(k) + float64((h>>42)&mask)/mask,
+	}
+}
+
+// Distances returns the distances to the nearest (f1) and second
+// nearest (f2) feature point
+func (w *Worley) Distances(x, y, z float64) (f1, f2 float64) {
+	i, j, k := int(math.Floor(x)), int(math.Floor(y)), int(math.Floor(z))
+	f1, f2 = math.Inf(1), math.Inf(1)
+
+	for di := -1; di <= 1; di++ {
+		for dj := -1; dj <= 1; dj++ {
+			for dk := -1; dk <= 1; dk++ {
+				p := w.featurePoint(i+di, j+dj, k+dk)
+				dx, dy, dz := p.x-x, p.y-y, p.z-z
+				d := math.Sqrt(dx*dx + dy*dy + dz*dz)
+
+				if d < f1 {
+					f1, f2 = d, f1
+				} else if d < f2 {
+					f2 = d
+				}
+			}
+		}
+	}
+
+	return f1, f2
+}
+
+// Noise returns the distance to the nearest feature point
+func (w *Worley) Noise(x, y, z float64) float64 {
+	f1, _ := w.Distances(x, y, z)
+	return f1
+}
@@ -0,0 +1,68 @@
+package raytracing
+
+import (
+	"math"
+
+	"github.com/davherrmann/rtgo/raytracing/noise"
+)
+
+type NoisePattern int
+
+const (
+	PerlinPattern NoisePattern = iota
+	TurbulencePattern
+	MarblePattern
+	WoodPattern
+	CellularPattern
+)
+
+const turbulenceDepth = 7
+
+// NoiseTexture blends between two colors with a procedural pattern
+// evaluated at the hit point
+type NoiseTexture struct {
+	Pattern NoisePattern
+	// Scale is the frequency of the pattern
+	Scale float64
+	A     Color
+	B     Color
+
+	perlin *noise.Perlin
+	worley *noise.Worley
+}
+
+func NewNoiseTexture(seed int64, pattern NoisePattern, scale float64, a, b Color) NoiseTexture {
+	return NoiseTexture{
+		Pattern: pattern,
+		Scale:   scale,
+		A:       a,
+		B:       b,
+		perlin:  noise.NewPerlin(seed),
+		worley:  noise.NewWorley(seed),
+	}
+}
+
+func (n NoiseTexture) Value(u, v float64, point Vec) Color {
+	t := n.intensity(point.Multiply(n.Scale))
+	t = math.Max(0, math.Min(1, t))
+
+	return n.A.Multiply(1 - t).Add(n.B.Multiply(t))
+}
+
+func (n NoiseTexture) intensity(p Vec) float64 {
+	switch n.Pattern {
+	case TurbulencePattern:
+		return n.perlin.Turbulence(p.X, p.Y, p.Z, turbulenceDepth)
+	case MarblePattern:
+		return 0.5 * (1 + math.Sin(p.Z+10*n.perlin.Turbulence(p.X, p.Y, p.Z, turbulenceDepth)))
+	case WoodPattern:
+		// concentric rings around the Y axis, distorted by noise
+		rings := math.Sqrt(p.X*p.X+p.Z*p.Z) + 0.3*n.perlin.Noise(p.X, p.Y, p.Z)
+		return rings - math.Floor(rings)
+	case CellularPattern:
+		f1, f2 := n.worley.Distances(p.X, p.Y, p.Z)
+		return f2 - f1
+	default:
+		return 0.5 * (1 + n.perlin.Noise(p.X, p.Y, p.Z))
+	}
+}
@@ -0,0 +1,47 @@
+package raytracing_test
+
+import (
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func TestNoiseTexture(t *testing.T) {
+	patterns := []raytracing.NoisePattern{
+		raytracing.PerlinPattern,
+		raytracing.TurbulencePattern,
+		raytracing.MarblePattern,
+		raytracing.WoodPattern,
+		raytracing.CellularPattern,
+	}
+	a := raytracing.Color{R: 1, G: 0.5}
+	b := raytracing.Color{G: 1, B: 0.25}
+
+	for _, pattern := range patterns {
+		texture := raytracing.NewNoiseTexture(42, pattern, 4, a, b)
+		same := raytracing.NewNoiseTexture(42, pattern, 4, a, b)
+
+		varies := false
+		first := texture.Value(0, 0, Vec{})
+		for i := 0; i < 200; i++ {
+			point := Vec{X: float64(i) * 0.137, Y: float64(i) * -0.071, Z: float64(i) * 0.053}
+			value := texture.Value(0, 0, point)
+
+			if value != same.Value(0, 0, point) {
+				t.Fatalf("pattern %d: same seed gives different colors at %v", pattern, point)
+			}
+			if value != first {
+				varies = true
+			}
+
+			// colors stay between a and b
+			if value.R < 0 || value.R > 1 || value.G < 0.5 || value.G > 1 || value.B < 0 || value.B > 0.25 {
+				t.Fatalf("pattern %d: color %v out of range at %v", pattern, value, point)
+			}
+		}
+
+		if !varies {
+			t.Errorf("pattern %d: expected the color to vary", pattern)
+		}
+	}
+}
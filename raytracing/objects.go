@@ -8,10 +8,15 @@ type Hit struct {
 	T         float64
 	FrontFace bool
 	Material  Material
+
+	// surface coordinates for textures
+	U float64
+	V float64
 }
 
 type Hittable interface {
 	Hit(ray Ray, tMin, tMax float64) *Hit
+	BoundingBox() AABB
 }
 
 type World struct {
@@ -23,21 +28,26 @@ func (w World) Hit(ray Ray, tMin, tMax float64) *Hit {
 	closest := tMax
 
 	for _, hittable := range w.Objects {
-		hit := hittable.Hit(ray, tMin, tMax)
+		hit := hittable.Hit(ray, tMin, closest)
 		if hit == nil {
 			continue
 		}
 
-		distance := hit.Point.Subtract(ray.Origin).Length()
-		if distance < closest {
-			closestHit = hit
-			closest = distance
-		}
+		closestHit = hit
+		closest = hit.T
 	}
 
 	return closestHit
 }
 
+func (w World) BoundingBox() AABB {
+	box := emptyAABB
+	for _, hittable := range w.Objects {
+		box = box.Union(hittable.BoundingBox())
+	}
+	return box
+}
+
 type Sphere struct {
 	Center   Vec
 	Radius   float64
@@ -75,11 +85,32 @@ func (s Sphere) Hit(ray Ray, tMin, tMax float64) *Hit {
 		normal = normal.Multiply(-1)
 	}
 
+	u, v := sphereUV(point.Subtract(s.Center).Multiply(1 / math.Abs(s.Radius)))
+
 	return &Hit{
 		Point:     point,
 		T:         root,
 		Normal:    normal,
 		Material:  s.Material,
 		FrontFace: frontFace,
+		U:         u,
+		V:         v,
+	}
+}
+
+// sphereUV maps a point on the unit sphere to longitude u and latitude v,
+// both in [0, 1], with v = 0 at the bottom
+func sphereUV(p Vec) (u, v float64) {
+	theta := math.Acos(math.Max(-1, math.Min(1, -p.Y)))
+	phi := math.Atan2(-p.Z, p.X) + math.Pi
+
+	return phi / (2 * math.Pi), theta / math.Pi
+}
+
+func (s Sphere) BoundingBox() AABB {
+	r := math.Abs(s.Radius)
+	return AABB{
+		Min: s.Center.Subtract(Vec{r, r, r}),
+		Max: s.Center.Add(Vec{r, r, r}),
 	}
 }
@@ -0,0 +1,90 @@
+package raytracing
+
+import "math"
+
+// PDF is a probability density over directions (solid angle)
+type PDF interface {
+	Value(direction Vec) float64
+	Generate(sampler Sampler) Vec
+}
+
+// ONB is an orthonormal basis with W as the main axis
+type ONB struct {
+	U Vec
+	V Vec
+	W Vec
+}
+
+func NewONB(w Vec) ONB {
+	w = w.Normalized()
+	a := Vec{X: 1}
+	if math.Abs(w.X) > 0.9 {
+		a = Vec{Y: 1}
+	}
+	v := w.Cross(a).Normalized()
+	u := w.Cross(v)
+
+	return ONB{U: u, V: v, W: w}
+}
+
+// Local converts coordinates relative to the basis into world space
+func (b ONB) Local(a Vec) Vec {
+	return b.U.Multiply(a.X).Add(b.V.Multiply(a.Y)).Add(b.W.Multiply(a.Z))
+}
+
+// CosinePDF samples the hemisphere around a normal proportional to the
+// cosine, matching the Lambertian BRDF
+type CosinePDF struct {
+	basis ONB
+}
+
+func NewCosinePDF(normal Vec) CosinePDF {
+	return CosinePDF{basis: NewONB(normal)}
+}
+
+func (p CosinePDF) Value(direction Vec) float64 {
+	cosine := direction.Normalized().Dot(p.basis.W)
+	return math.Max(cosine, 0) / math.Pi
+}
+
+func (p CosinePDF) Generate(sampler Sampler) Vec {
+	return p.basis.Local(randomCosineDirection(sampler))
+}
+
+func randomCosineDirection(sampler Sampler) Vec {
+	r1 := sampler.Float64()
+	r2 := sampler.Float64()
+
+	phi := 2 * math.Pi * r1
+	r := math.Sqrt(r2)
+
+	return Vec{X: math.Cos(phi) * r, Y: math.Sin(phi) * r, Z: math.Sqrt(1 - r2)}
+}
+
+type UniformSpherePDF struct{}
+
+func (UniformSpherePDF) Value(direction Vec) float64 {
+	return 1 / (4 * math.Pi)
+}
+
+func (UniformSpherePDF) Generate(sampler Sampler) Vec {
+	return randomUnitVector(sampler)
+}
+
+// MixturePDF picks A with probability Weight and B otherwise
+type MixturePDF struct {
+	A      PDF
+	B      PDF
+	Weight float64
+}
+
+func (p MixturePDF) Value(direction Vec) float64 {
+	return p.Weight*p.A.Value(direction) + (1-p.Weight)*p.B.Value(direction)
+}
+
+func (p MixturePDF) Generate(sampler Sampler) Vec {
+	if sampler.Float64() < p.Weight {
+		return p.A.Generate(sampler)
+	}
+	return p.B.Generate(sampler)
+}
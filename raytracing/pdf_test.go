@@ -0,0 +1,48 @@
+package raytracing_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func TestONB(t *testing.T) {
+	for _, w := range []Vec{{X: 1}, {Y: 1}, {Z: -1}, {X: 1, Y: 2, Z: 3}} {
+		basis := raytracing.NewONB(w)
+		requireEqual(t, basis.U.Length(), 1)
+		requireEqual(t, basis.V.Length(), 1)
+		requireEqual(t, basis.U.Dot(basis.V), 0)
+		requireEqual(t, basis.U.Dot(basis.W), 0)
+		requireEqual(t, basis.V.Dot(basis.W), 0)
+		requireEqual(t, basis.Local(Vec{Z: 1}).Dot(w.Normalized()), 1)
+	}
+}
+
+func TestPDFIntegratesToOne(t *testing.T) {
+	rng := raytracing.NewRNG(1)
+	normal := Vec{X: 1, Y: 1}.Normalized()
+	pdfs := []raytracing.PDF{
+		raytracing.NewCosinePDF(normal),
+		raytracing.UniformSpherePDF{},
+		raytracing.MixturePDF{A: raytracing.NewCosinePDF(normal), B: raytracing.UniformSpherePDF{}, Weight: 0.3},
+	}
+
+	for _, pdf := range pdfs {
+		// estimate the integral of the density over the sphere with uniform samples
+		const samples = 200000
+		sum := 0.0
+		for i := 0; i < samples; i++ {
+			sum += pdf.Value(raytracing.UniformSpherePDF{}.Generate(rng)) * 4 * math.Pi
+		}
+		if integral := sum / samples; math.Abs(integral-1) > 0.02 {
+			t.Errorf("%T: expected pdf to integrate to 1, got %v", pdf, integral)
+		}
+
+		for i := 0; i < 100; i++ {
+			if pdf.Value(pdf.Generate(rng)) <= 0 {
+				t.Fatalf("%T: generated direction with zero density", pdf)
+			}
+		}
+	}
+}
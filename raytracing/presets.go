@@ -0,0 +1,28 @@
+package raytracing
+
+import "fmt"
+
+// presets are common materials that scenes can reference by name. The
+// complex indices of refraction are sampled at 650, 550 and 450 nm.
+var presets = map[string]func() Material{
+	"gold":      func() Material { return Conductor(Color{0.143, 0.374, 1.442}, Color{3.983, 2.385, 1.603}, 0.1) },
+	"silver":    func() Material { return Conductor(Color{0.155, 0.117, 0.138}, Color{4.828, 3.122, 2.147}, 0.1) },
+	"copper":    func() Material { return Conductor(Color{0.200, 0.924, 1.102}, Color{3.912, 2.452, 2.142}, 0.1) },
+	"aluminium": func() Material { return Conductor(Color{1.657, 0.880, 0.521}, Color{9.224, 6.270, 4.837}, 0.2) },
+	"chrome":    func() Material { return Conductor(Color{3.170, 3.110, 2.410}, Color{3.300, 3.330, 3.270}, 0.05) },
+	"glass":     func() Material { return Dielectric(1.5) },
+	"water":     func() Material { return Dielectric(1.333) },
+	"diamond":   func() Material { return Dielectric(2.42) },
+	"plastic": func() Material {
+		return Principled(PrincipledOptions{BaseColor: Color{0.8, 0.8, 0.8}, Roughness: 0.3})
+	},
+}
+
+// Preset returns a new instance of the preset material with the given name
+func Preset(name string) (Material, error) {
+	preset, ok := presets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q", name)
+	}
+	return preset(), nil
+}
@@ -0,0 +1,75 @@
+package raytracing
+
+import "math"
+
+// Projection maps film coordinates s (rightwards) and t (upwards) in [0, 1]
+// to a ray in camera space, where the camera looks down -Z with +Y up. A
+// zero direction marks film coordinates outside of the projection.
+type Projection interface {
+	Project(s, t, aspectRatio float64) (origin, direction Vec)
+}
+
+// Perspective is a pinhole projection with a vertical field of view in
+// radians
+type Perspective struct {
+	VerticalFOV float64
+}
+
+func (p Perspective) Project(s, t, aspectRatio float64) (origin, direction Vec) {
+	height := 2 * math.Tan(p.VerticalFOV/2)
+	return Vec{}, Vec{
+		X: (s - 0.5) * height * aspectRatio,
+		Y: (t - 0.5) * height,
+		Z: -1,
+	}
+}
+
+// Orthographic sends parallel rays from a film of the given height
+type Orthographic struct {
+	Height float64
+}
+
+func (p Orthographic) Project(s, t, aspectRatio float64) (origin, direction Vec) {
+	return Vec{
+		X: (s - 0.5) * p.Height * aspectRatio,
+		Y: (t - 0.5) * p.Height,
+	}, Vec{Z: -1}
+}
+
+// Fisheye is an equidistant fisheye projection into the circle fitting the
+// image height, FOV in radians is the angle across that circle
+type Fisheye struct {
+	FOV float64
+}
+
+func (p Fisheye) Project(s, t, aspectRatio float64) (origin, direction Vec) {
+	x := (2*s - 1) * aspectRatio
+	y := 2*t - 1
+	r := math.Sqrt(x*x + y*y)
+	if r > 1 {
+		return Vec{}, Vec{}
+	}
+
+	theta := r * p.FOV / 2
+	phi := math.Atan2(y, x)
+	return Vec{}, Vec{
+		X: math.Sin(theta) * math.Cos(phi),
+		Y: math.Sin(theta) * math.Sin(phi),
+		Z: -math.Cos(theta),
+	}
+}
+
+// Equirectangular covers all directions, longitude along s and latitude
+// along t, with the view direction in the center. Images should have an
+// aspect ratio of 2:1.
+type Equirectangular struct{}
+
+func (Equirectangular) Project(s, t, aspectRatio float64) (origin, direction Vec) {
+	longitude := (s - 0.5) * 2 * math.Pi
+	latitude := (t - 0.5) * math.Pi
+	return Vec{}, Vec{
+		X: math.Cos(latitude) * math.Sin(longitude),
+		Y: math.Sin(latitude),
+		Z: -math.Cos(latitude) * math.Cos(longitude),
+	}
+}
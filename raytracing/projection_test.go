@@ -0,0 +1,51 @@
+package raytracing_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func TestProjections(t *testing.T) {
+	camera := raytracing.Camera{Up: Vec{Y: 1}, From: Vec{Z: 5}, LookAt: Vec{}}
+
+	tests := []struct {
+		Projection raytracing.Projection
+		S, T       float64
+		Origin     Vec
+		Direction  Vec
+	}{
+		// the center always looks at LookAt
+		{raytracing.Perspective{VerticalFOV: 1}, 0.5, 0.5, Vec{Z: 5}, Vec{Z: -1}},
+		{raytracing.Orthographic{Height: 2}, 0.5, 0.5, Vec{Z: 5}, Vec{Z: -1}},
+		{raytracing.Fisheye{FOV: math.Pi}, 0.5, 0.5, Vec{Z: 5}, Vec{Z: -1}},
+		{raytracing.Equirectangular{}, 0.5, 0.5, Vec{Z: 5}, Vec{Z: -1}},
+
+		// top edge of a 90° perspective
+		{raytracing.Perspective{VerticalFOV: math.Pi / 2}, 0.5, 1, Vec{Z: 5}, Vec{Y: 1, Z: -1}.Normalized()},
+		// orthographic rays are parallel
+		{raytracing.Orthographic{Height: 2}, 1, 1, Vec{X: 1, Y: 1, Z: 5}, Vec{Z: -1}},
+		// the rim of a 180° fisheye looks sideways
+		{raytracing.Fisheye{FOV: math.Pi}, 1, 0.5, Vec{Z: 5}, Vec{X: 1}},
+		// equirectangular covers all directions
+		{raytracing.Equirectangular{}, 0.75, 0.5, Vec{Z: 5}, Vec{X: 1}},
+		{raytracing.Equirectangular{}, 0, 0.5, Vec{Z: 5}, Vec{Z: 1}},
+		{raytracing.Equirectangular{}, 0.5, 1, Vec{Z: 5}, Vec{Y: 1}},
+	}
+
+	for _, test := range tests {
+		camera.Projection = test.Projection
+		ray := camera.RayCaster(1)(test.S, test.T, raytracing.NewRNG(1))
+
+		requireEqual(t, ray.Origin.Subtract(test.Origin).Length(), 0)
+		requireEqual(t, ray.Direction.Normalized().Dot(test.Direction), 1)
+	}
+}
+
+func TestFisheyeOutsideOfCircle(t *testing.T) {
+	camera := raytracing.Camera{Up: Vec{Y: 1}, From: Vec{Z: 5}, Projection: raytracing.Fisheye{FOV: math.Pi}}
+	if ray := camera.RayCaster(2)(0, 0.5, raytracing.NewRNG(1)); ray.Direction != (Vec{}) {
+		t.Errorf("expected no ray outside of the fisheye circle, got %v", ray.Direction)
+	}
+}
@@ -0,0 +1,69 @@
+package raytracing
+
+import "math"
+
+// Quad is a parallelogram spanned by the edges U and V from corner Q
+type Quad struct {
+	Q        Vec
+	U        Vec
+	V        Vec
+	Material Material
+}
+
+func (q Quad) Hit(ray Ray, tMin, tMax float64) *Hit {
+	n := q.U.Cross(q.V)
+	denom := n.Dot(ray.Direction)
+
+	// ray parallel to the plane
+	if math.Abs(denom) < 1e-12 {
+		return nil
+	}
+
+	t := (n.Dot(q.Q) - n.Dot(ray.Origin)) / denom
+	if t < tMin || tMax < t {
+		return nil
+	}
+
+	// planar coordinates of the hit point
+	w := n.Multiply(1 / n.LengthSquared())
+	planar := ray.At(t).Subtract(q.Q)
+	alpha := w.Dot(planar.Cross(q.V))
+	beta := w.Dot(q.U.Cross(planar))
+	if alpha < 0 || alpha > 1 || beta < 0 || beta > 1 {
+		return nil
+	}
+
+	return planeHit(ray, t, n.Normalized(), alpha, beta, q.Material)
+}
+
+func (q Quad) BoundingBox() AABB {
+	opposite := q.Q.Add(q.U).Add(q.V)
+	return AABB{
+		Min: q.Q.Min(opposite).Min(q.Q.Add(q.U)).Min(q.Q.Add(q.V)),
+		Max: q.Q.Max(opposite).Max(q.Q.Add(q.U)).Max(q.Q.Add(q.V)),
+	}.Padded(rectThickness)
+}
+
+// Box is an axis-aligned box made of six quads
+type Box struct {
+	World
+}
+
+func NewBox(a, b Vec, material Material) Box {
+	min := a.Min(b)
+	max := a.Max(b)
+
+	dx := Vec{X: max.X - min.X}
+	dy := Vec{Y: max.Y - min.Y}
+	dz := Vec{Z: max.Z - min.Z}
+
+	// edges are ordered so that all normals point outwards
+	return Box{World{Objects: []Hittable{
+		Quad{Q: Vec{X: min.X, Y: min.Y, Z: max.Z}, U: dx, V: dy, Material: material},              // front
+		Quad{Q: Vec{X: max.X, Y: min.Y, Z: max.Z}, U: dz.Multiply(-1), V: dy, Material: material}, // right
+		Quad{Q: Vec{X: max.X, Y: min.Y, Z: min.Z}, U: dx.Multiply(-1), V: dy, Material: material}, // back
+		Quad{Q: Vec{X: min.X, Y: min.Y, Z: min.Z}, U: dz, V: dy, Material: material},              // left
+		Quad{Q: Vec{X: min.X, Y: max.Y, Z: max.Z}, U: dx, V: dz.Multiply(-1), Material: material}, // top
+		Quad{Q: Vec{X: min.X, Y: min.Y, Z: min.Z}, U: dx, V: dz, Material: material},              // bottom
+	}}}
+}
@@ -0,0 +1,41 @@
+package raytracing_test
+
+import (
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func TestBoxNormals(t *testing.T) {
+	box := raytracing.NewBox(Vec{X: -1, Y: -1, Z: -1}, Vec{X: 1, Y: 1, Z: 1}, nil)
+
+	directions := []Vec{{X: 1}, {X: -1}, {Y: 1}, {Y: -1}, {Z: 1}, {Z: -1}}
+	for _, direction := range directions {
+		// shoot from outside towards the center
+		ray := raytracing.Ray{Origin: direction.Multiply(3), Direction: direction.Multiply(-1)}
+		hit := box.Hit(ray, 0.001, 10000)
+		if hit == nil {
+			t.Fatalf("expected hit from %v", direction)
+		}
+
+		requireEqual(t, hit.T, 2)
+		requireEqual(t, hit.Normal, direction)
+		if !hit.FrontFace {
+			t.Errorf("expected front face hit from %v", direction)
+		}
+	}
+}
+
+func TestQuadMiss(t *testing.T) {
+	quad := raytracing.Quad{Q: Vec{}, U: Vec{X: 1}, V: Vec{X: 1, Y: 1}}
+
+	hit := quad.Hit(raytracing.Ray{Origin: Vec{X: 0.1, Y: 0.5, Z: 1}, Direction: Vec{Z: -1}}, 0.001, 10000)
+	if hit != nil {
+		t.Errorf("expected miss outside of the parallelogram")
+	}
+
+	hit = quad.Hit(raytracing.Ray{Origin: Vec{X: 1, Y: 0.5, Z: 1}, Direction: Vec{Z: -1}}, 0.001, 10000)
+	if hit == nil {
+		t.Errorf("expected hit inside of the parallelogram")
+	}
+}
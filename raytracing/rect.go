@@ -0,0 +1,109 @@
+package raytracing
+
+// axis-aligned rectangles, e.g. for Cornell box walls
+
+// rectThickness pads the flat bounding boxes of rectangles
+const rectThickness = 1e-4
+
+type XYRect struct {
+	X0, X1   float64
+	Y0, Y1   float64
+	K        float64
+	Material Material
+}
+
+type XZRect struct {
+	X0, X1   float64
+	Z0, Z1   float64
+	K        float64
+	Material Material
+}
+
+type YZRect struct {
+	Y0, Y1   float64
+	Z0, Z1   float64
+	K        float64
+	Material Material
+}
+
+// planeHit builds a hit with the normal pointing against the ray
+func planeHit(ray Ray, t float64, outwardNormal Vec, u, v float64, material Material) *Hit {
+	frontFace := ray.Direction.Dot(outwardNormal) < 0
+	normal := outwardNormal
+	if !frontFace {
+		normal = normal.Multiply(-1)
+	}
+
+	return &Hit{
+		Point:     ray.At(t),
+		T:         t,
+		Normal:    normal,
+		Material:  material,
+		FrontFace: frontFace,
+		U:         u,
+		V:         v,
+	}
+}
+
+func (r XYRect) Hit(ray Ray, tMin, tMax float64) *Hit {
+	t := (r.K - ray.Origin.Z) / ray.Direction.Z
+	if !(t >= tMin && t <= tMax) {
+		return nil
+	}
+
+	x := ray.Origin.X + t*ray.Direction.X
+	y := ray.Origin.Y + t*ray.Direction.Y
+	if x < r.X0 || x > r.X1 || y < r.Y0 || y > r.Y1 {
+		return nil
+	}
+
+	u := (x - r.X0) / (r.X1 - r.X0)
+	v := (y - r.Y0) / (r.Y1 - r.Y0)
+	return planeHit(ray, t, Vec{Z: 1}, u, v, r.Material)
+}
+
+func (r XYRect) BoundingBox() AABB {
+	return AABB{Min: Vec{X: r.X0, Y: r.Y0, Z: r.K}, Max: Vec{X: r.X1, Y: r.Y1, Z: r.K}}.Padded(rectThickness)
+}
+
+func (r XZRect) Hit(ray Ray, tMin, tMax float64) *Hit {
+	t := (r.K - ray.Origin.Y) / ray.Direction.Y
+	if !(t >= tMin && t <= tMax) {
+		return nil
+	}
+
+	x := ray.Origin.X + t*ray.Direction.X
+	z := ray.Origin.Z + t*ray.Direction.Z
+	if x < r.X0 || x > r.X1 || z < r.Z0 || z > r.Z1 {
+		return nil
+	}
+
+	u := (x - r.X0) / (r.X1 - r.X0)
+	v := (z - r.Z0) / (r.Z1 - r.Z0)
+	return planeHit(ray, t, Vec{Y: 1}, u, v, r.Material)
+}
+
+func (r XZRect) BoundingBox() AABB {
+	return AABB{Min: Vec{X: r.X0, Y: r.K, Z: r.Z0}, Max: Vec{X: r.X1, Y: r.K, Z: r.Z1}}.Padded(rectThickness)
+}
+
+func (r YZRect) Hit(ray Ray, tMin, tMax float64) *Hit {
+	t := (r.K - ray.Origin.X) / ray.Direction.X
+	if !(t >= tMin && t <= tMax) {
+		return nil
+	}
+
+	y := ray.Origin.Y + t*ray.Direction.Y
+	z := ray.Origin.Z + t*ray.Direction.Z
+	if y < r.Y0 || y > r.Y1 || z < r.Z0 || z > r.Z1 {
+		return nil
+	}
+
+	u := (y - r.Y0) / (r.Y1 - r.Y0)
+	v := (z - r.Z0) / (r.Z1 - r.Z0)
+	return planeHit(ray, t, Vec{X: 1}, u, v, r.Material)
+}
+
+func (r YZRect) BoundingBox() AABB {
+	return AABB{Min: Vec{X: r.K, Y: r.Y0, Z: r.Z0}, Max: Vec{X: r.K, Y: r.Y1, Z: r.Z1}}.Padded(rectThickness)
+}
@@ -2,109 +2,443 @@ package raytracing
 
 import (
 	"context"
+	"fmt"
 	"image/color"
 	"math"
-	"math/rand"
+	"runtime"
+	"sync"
 )
 
+// RenderOptions configure a single call to Render, zero values are
+// replaced by defaults
 type RenderOptions struct {
 	ResolutionX int
 	ResolutionY int
 
 	SamplesPerPixel int
 	MaxBounces      int
+	Gamma           float64
+
+	// TMin and TMax bound the distances at which rays hit objects, TMin
+	// avoids self intersections
+	TMin float64
+	TMax float64
+
+	// Background overrides the background of the scene. Distant lights,
+	// like the sun of a sky or an environment map, belong to the replaced
+	// background and are dropped with it.
+	Background Background
+
+	// Workers rendering tiles in parallel, zero uses all CPUs
+	Workers int
+
+	// Seed makes renders reproducible, the same seed gives the same image
+	// regardless of the number of workers
+	Seed    uint64
+	Sampler SamplerType
+
+	// AdaptiveThreshold stops sampling a pixel once the 95% confidence
+	// interval of its luminance is narrower than this fraction of its mean,
+	// SamplesPerPixel is the budget of noisy pixels then. Zero samples
+	// every pixel SamplesPerPixel times.
+	AdaptiveThreshold float64
+	// MinSamples every pixel gets before adaptive sampling may stop it
+	MinSamples int
+	// Heatmap optionally receives the number of samples spent on each
+	// pixel once the render is complete, from black over red and yellow to
+	// white for SamplesPerPixel
+	Heatmap func(x, y int, color color.RGBA)
+}
+
+var DefaultRenderOptions = RenderOptions{
+	ResolutionX:     400,
+	ResolutionY:     300,
+	SamplesPerPixel: 10,
+	MaxBounces:      10,
+	Gamma:           2,
+	TMin:            0.001,
+	TMax:            math.Inf(1),
+	MinSamples:      8,
 }
 
-type RayCaster func(u, v float64) Ray
+// Validate reports options which can't be rendered, zero values are
+// checked after being replaced by their defaults
+func (o RenderOptions) Validate() error {
+	o = o.withDefaults()
+	switch {
+	case o.ResolutionX < 0 || o.ResolutionY < 0 || o.ResolutionX == 1 || o.ResolutionY == 1:
+		return fmt.Errorf("invalid resolution %dx%d", o.ResolutionX, o.ResolutionY)
+	case o.SamplesPerPixel < 0:
+		return fmt.Errorf("invalid samples per pixel %d", o.SamplesPerPixel)
+	case o.MaxBounces < 0:
+		return fmt.Errorf("invalid max bounces %d", o.MaxBounces)
+	case o.Gamma < 0 || math.IsNaN(o.Gamma) || math.IsInf(o.Gamma, 0):
+		return fmt.Errorf("invalid gamma %v", o.Gamma)
+	case o.TMin < 0 || math.IsNaN(o.TMin) || math.IsNaN(o.TMax) || o.TMax <= o.TMin:
+		return fmt.Errorf("invalid ray interval [%v, %v]", o.TMin, o.TMax)
+	case o.Workers < 0:
+		return fmt.Errorf("invalid number of workers %d", o.Workers)
+	case o.Sampler < IndependentSampler || o.Sampler > SobolSampler:
+		return fmt.Errorf("invalid sampler %v", o.Sampler)
+	case o.AdaptiveThreshold < 0 || math.IsNaN(o.AdaptiveThreshold):
+		return fmt.Errorf("invalid adaptive threshold %v", o.AdaptiveThreshold)
+	case o.MinSamples < 0:
+		return fmt.Errorf("invalid min samples %d", o.MinSamples)
+	}
+	return nil
+}
+
+func (o RenderOptions) withDefaults() RenderOptions {
+	if o.ResolutionX == 0 {
+		o.ResolutionX = DefaultRenderOptions.ResolutionX
+	}
+	if o.ResolutionY == 0 {
+		o.ResolutionY = DefaultRenderOptions.ResolutionY
+	}
+	if o.SamplesPerPixel == 0 {
+		o.SamplesPerPixel = DefaultRenderOptions.SamplesPerPixel
+	}
+	if o.MaxBounces == 0 {
+		o.MaxBounces = DefaultRenderOptions.MaxBounces
+	}
+	if o.Gamma == 0 {
+		o.Gamma = DefaultRenderOptions.Gamma
+	}
+	if o.TMin == 0 {
+		o.TMin = DefaultRenderOptions.TMin
+	}
+	if o.TMax == 0 {
+		o.TMax = DefaultRenderOptions.TMax
+	}
+	if o.Workers == 0 {
+		o.Workers = runtime.NumCPU()
+	}
+	if o.MinSamples == 0 {
+		o.MinSamples = DefaultRenderOptions.MinSamples
+	}
+	return o
+}
+
+type RayCaster func(u, v float64, sampler Sampler) Ray
 
 type Vec = Vector
 
 type Ray struct {
 	Origin    Vec
 	Direction Vec
+	Time      float64
+	// Sampler of the pixel sample the ray belongs to, volumes draw their
+	// scattering distances from it
+	Sampler Sampler
 }
 
 func (r *Ray) At(t float64) Vec {
 	return r.Origin.Add(r.Direction.Multiply(t))
 }
 
-func randomUnitVector() Vec {
-	return Vec{rand.Float64()*2 - 1, rand.Float64()*2 - 1, rand.Float64()*2 - 1}.Normalized()
+// randomUnitVector is uniformly distributed on the unit sphere, which
+// isotropic phase functions rely on
+func randomUnitVector(sampler Sampler) Vec {
+	z := 1 - 2*sampler.Float64()
+	r := math.Sqrt(math.Max(0, 1-z*z))
+	phi := 2 * math.Pi * sampler.Float64()
+	return Vec{r * math.Cos(phi), r * math.Sin(phi), z}
 }
 
-var samplesPerPixel = 10
-var maxBounces = 10
+// powerHeuristic is the multiple importance sampling weight for a sample
+// drawn with pdf a, combined with a strategy with pdf b
+func powerHeuristic(a, b float64) float64 {
+	return a * a / (a*a + b*b)
+}
 
-func rayColor(world Hittable, ray Ray, bounces int) Color {
-	if bounces >= maxBounces {
-		return Black
-	}
+func rayColor(scene Scene, ray Ray, options RenderOptions, sampler Sampler) Color {
+	radiance := Black
+	throughput := Color{1, 1, 1}
 
-	hit := world.Hit(ray, 0.001, 10000)
-	if hit != nil {
-		materialHit := hit.Material(ray, *hit)
+	// density of the non-specular bounce that produced ray, zero for camera
+	// rays and specular bounces which can't be sampled by lights
+	bsdfPDF := 0.0
+
+	for bounces := 0; bounces < options.MaxBounces; bounces++ {
+		hit := scene.World.Hit(ray, options.TMin, options.TMax)
+		if hit == nil {
+			background := scene.background(ray.Direction)
+			if bsdfPDF > 0 {
+				background = background.Multiply(powerHeuristic(bsdfPDF, scene.lightPDF(ray.Origin, ray.Direction)))
+			}
+			return radiance.Add(throughput.Mix(background))
+		}
 
+		materialHit := hit.Material(ray, *hit, sampler)
 		if materialHit == nil {
-			return Black
+			break
 		}
 
-		return rayColor(world, materialHit.Scattered, bounces+1).Mix(materialHit.Attenuation)
+		// emitters found by BSDF sampling are weighted against light sampling
+		emitted := materialHit.Emitted
+		if bsdfPDF > 0 && emitted != Black {
+			emitted = emitted.Multiply(powerHeuristic(bsdfPDF, scene.lightPDF(ray.Origin, ray.Direction)))
+		}
+		radiance = radiance.Add(throughput.Mix(emitted))
+
+		// specular bounce
+		if materialHit.BSDF == nil {
+			if materialHit.Scattered == nil {
+				break
+			}
+			bsdfPDF = 0
+			throughput = throughput.Mix(materialHit.Attenuation)
+			ray = *materialHit.Scattered
+			continue
+		}
+
+		radiance = radiance.Add(throughput.Mix(sampleLight(scene, ray, hit, materialHit, options, sampler)))
+
+		direction := materialHit.PDF.Generate(sampler)
+		bsdfPDF = materialHit.PDF.Value(direction)
+		if bsdfPDF <= 0 {
+			break
+		}
+
+		throughput = throughput.Mix(materialHit.BSDF(direction).Multiply(1 / bsdfPDF))
+		ray = Ray{hit.Point, direction, ray.Time, sampler}
+	}
+
+	return radiance
+}
+
+// sampleLight estimates the direct light at a non-specular hit from one
+// randomly chosen light (next-event estimation)
+func sampleLight(scene Scene, ray Ray, hit *Hit, materialHit *MaterialHit, options RenderOptions, sampler Sampler) Color {
+	if len(scene.Lights) == 0 {
+		return Black
 	}
 
-	t := 0.5 * (ray.Direction.Normalized().Y + 1)
+	light := scene.Lights[sampleIndex(sampler, len(scene.Lights))]
+	sample := light.Sample(hit.Point, sampler)
+	if sample.PDF <= 0 {
+		return Black
+	}
 
-	return Color{1, 1, 1}.Multiply(1 - t).Add(Color{0.5, 0.7, 1.0}.Multiply(t))
+	bsdf := materialHit.BSDF(sample.Direction)
+	if bsdf == Black {
+		return Black
+	}
+
+	// shadow ray, stopping short of the light itself
+	shadowRay := Ray{hit.Point, sample.Direction, ray.Time, sampler}
+	if scene.World.Hit(shadowRay, options.TMin, math.Min(sample.Distance*(1-1e-4), options.TMax)) != nil {
+		return Black
+	}
+
+	lightPDF := sample.PDF / float64(len(scene.Lights))
+	weight := 1.0
+	if !sample.Delta {
+		weight = powerHeuristic(lightPDF, materialHit.PDF.Value(sample.Direction))
+	}
+
+	return sample.Radiance.Mix(bsdf).Multiply(weight / lightPDF)
 }
 
 type drawFn func(x, y int, color color.RGBA)
 
-func Render(ctx context.Context, world Hittable, camera Camera, options RenderOptions, drawFn drawFn) {
+// pixelStats is the running mean and variance of the luminance of the
+// samples of a pixel (Welford's algorithm)
+type pixelStats struct {
+	samples int
+	mean    float64
+	m2      float64
+}
+
+func (p *pixelStats) add(value float64) {
+	p.samples++
+	delta := value - p.mean
+	p.mean += delta / float64(p.samples)
+	p.m2 += delta * (value - p.mean)
+}
+
+// converged reports whether the 95% confidence interval of the mean is
+// narrower than threshold relative to the mean, dark pixels are compared
+// against a floor so they don't need an excessive number of samples
+func (p *pixelStats) converged(threshold float64) bool {
+	if p.samples < 2 {
+		return false
+	}
+	variance := p.m2 / float64(p.samples-1)
+	interval := 1.96 * math.Sqrt(variance/float64(p.samples))
+	return interval <= threshold*math.Max(p.mean, 0.01)
+}
+
+// heatColor maps t in [0, 1] to black, red, yellow and white
+func heatColor(t float64) color.RGBA {
+	channel := func(v float64) uint8 {
+		return uint8(math.Max(0, math.Min(v, 1)) * 0xff)
+	}
+	return color.RGBA{
+		R: channel(3 * t),
+		G: channel(3*t - 1),
+		B: channel(3*t - 2),
+	}
+}
+
+// tileSize is the edge length of the square tiles workers render
+const tileSize = 16
+
+type tile struct {
+	x0, y0, x1, y1 int
+}
+
+// Render draws the image progressively, it stops early when ctx is done
+func Render(ctx context.Context, scene Scene, camera Camera, options RenderOptions, drawFn drawFn) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	options = options.withDefaults()
+	if options.Background != nil {
+		scene.Background = options.Background
+		scene.Lights = withoutDistantLights(scene.Lights)
+	}
+
 	aspectRatio := float64(options.ResolutionX) / float64(options.ResolutionY)
 	rayCaster := camera.RayCaster(aspectRatio)
 
 	width := options.ResolutionX
 	height := options.ResolutionY
 	colorSums := make([]Color, width*height)
+	stats := make([]pixelStats, width*height)
+	// converged pixels are skipped by later passes
+	converged := make([]bool, width*height)
 
-	// TODO parallelize
-	for s := 0; s < samplesPerPixel; s++ {
-		for y := height - 1; y >= 0; y-- {
-			for x := 0; x < width; x++ {
+	var tiles []tile
+	for y := 0; y < height; y += tileSize {
+		for x := 0; x < width; x += tileSize {
+			tiles = append(tiles, tile{x, y, minInt(x+tileSize, width), minInt(y+tileSize, height)})
+		}
+	}
+
+	// drawFn is called from all workers
+	var drawLock sync.Mutex
+
+	renderTile := func(t tile, s int) {
+		for y := t.y1 - 1; y >= t.y0; y-- {
+			for x := t.x0; x < t.x1; x++ {
 				if ctx.Err() != nil {
 					return
 				}
 
-				u := (float64(x) + rand.Float64()) / float64(width-1)
-				v := (float64(y) + rand.Float64()) / float64(height-1)
+				i := y*width + x
+				if converged[i] {
+					continue
+				}
 
-				ray := rayCaster(u, v)
-				singleColor := rayColor(world, ray, 0)
+				sampler := NewSampler(options.Sampler, options.Seed, x, y, s, options.SamplesPerPixel)
+
+				u := (float64(x) + sampler.Float64()) / float64(width-1)
+				v := (float64(y) + sampler.Float64()) / float64(height-1)
+
+				ray := rayCaster(u, v, sampler)
+
+				// rays outside of the projection stay black
+				singleColor := Black
+				if ray.Direction != (Vec{}) {
+					singleColor = rayColor(scene, ray, options, sampler)
+				}
 
-				i := y*width + x
 				colorSums[i] = colorSums[i].Add(singleColor)
+				stats[i].add(luminance(singleColor))
 
-				// send first, every nth and last sample
-				if s == 0 || s%3 == 0 || s == samplesPerPixel-1 {
+				if options.AdaptiveThreshold > 0 && s+1 >= options.MinSamples {
+					converged[i] = stats[i].converged(options.AdaptiveThreshold)
+				}
+
+				// send first, every nth, last sample and once converged
+				if s == 0 || s%3 == 0 || s == options.SamplesPerPixel-1 || converged[i] {
 					// average color
 					averageColor := colorSums[i].Multiply(1 / float64(s+1))
 
 					// gamma correction
-					gamma := 2.0
 					gammaCorrected := Color{
-						R: math.Pow(averageColor.R, 1/gamma),
-						G: math.Pow(averageColor.G, 1/gamma),
-						B: math.Pow(averageColor.B, 1/gamma),
+						R: math.Pow(averageColor.R, 1/options.Gamma),
+						G: math.Pow(averageColor.G, 1/options.Gamma),
+						B: math.Pow(averageColor.B, 1/options.Gamma),
 					}
 
-					// convert color
+					// convert color, light sources can exceed 1
 					converted := color.RGBA{
-						R: uint8(gammaCorrected.R * 0xff),
-						G: uint8(gammaCorrected.G * 0xff),
-						B: uint8(gammaCorrected.B * 0xff),
+						R: uint8(math.Min(gammaCorrected.R, 1) * 0xff),
+						G: uint8(math.Min(gammaCorrected.G, 1) * 0xff),
+						B: uint8(math.Min(gammaCorrected.B, 1) * 0xff),
 					}
+
+					drawLock.Lock()
 					drawFn(x, height-y, converted)
+					drawLock.Unlock()
 				}
 			}
 		}
 	}
+
+	// every pass adds one sample to each pixel, so the image refines
+	// progressively
+	for s := 0; s < options.SamplesPerPixel; s++ {
+		queue := make(chan tile)
+
+		var wg sync.WaitGroup
+		for w := 0; w < options.Workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for t := range queue {
+					renderTile(t, s)
+				}
+			}()
+		}
+
+	enqueue:
+		for _, t := range tiles {
+			select {
+			case queue <- t:
+			case <-ctx.Done():
+				break enqueue
+			}
+		}
+		close(queue)
+		wg.Wait()
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	if options.Heatmap != nil {
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				samples := stats[y*width+x].samples
+				options.Heatmap(x, height-y, heatColor(float64(samples)/float64(options.SamplesPerPixel)))
+			}
+		}
+	}
+
+	return nil
+}
+
+// withoutDistantLights returns the lights which are not infinitely far
+// away
+func withoutDistantLights(lights []Light) []Light {
+	var local []Light
+	for _, light := range lights {
+		switch light.(type) {
+		case DirectionalLight, *DirectionalLight, *EnvironmentMap:
+			continue
+		}
+		local = append(local, light)
+	}
+	return local
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
 }
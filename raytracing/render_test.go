@@ -0,0 +1,209 @@
+package raytracing_test
+
+import (
+	"context"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func testScene() (raytracing.Scene, raytracing.Camera) {
+	scene := raytracing.Scene{
+		World: raytracing.World{Objects: []raytracing.Hittable{
+			raytracing.Sphere{Center: Vec{Z: -1}, Radius: 0.5, Material: raytracing.Lambertian(raytracing.Color{R: 0.5, G: 0.5, B: 0.5})},
+		}},
+		Background: raytracing.SkyGradient(),
+	}
+	camera := raytracing.Camera{Up: Vec{Y: 1}, LookAt: Vec{Z: -1}, Zoom: 2}
+	return scene, camera
+}
+
+func TestRenderParallel(t *testing.T) {
+	scene, camera := testScene()
+	options := raytracing.RenderOptions{ResolutionX: 37, ResolutionY: 21, Workers: 4}
+
+	// drawFn is never called concurrently, so no locking needed here
+	drawn := make(map[[2]int]int)
+	raytracing.Render(context.Background(), scene, camera, options, func(x, y int, c color.RGBA) {
+		drawn[[2]int{x, y}]++
+	})
+
+	if len(drawn) != options.ResolutionX*options.ResolutionY {
+		t.Errorf("expected every pixel to be drawn, got %d", len(drawn))
+	}
+}
+
+func TestRenderCancel(t *testing.T) {
+	scene, camera := testScene()
+	options := raytracing.RenderOptions{ResolutionX: 400, ResolutionY: 300}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		raytracing.Render(ctx, scene, camera, options, func(x, y int, c color.RGBA) {
+			cancel()
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected render to stop after cancellation")
+	}
+}
+
+func TestRenderOptionsValidate(t *testing.T) {
+	scene, camera := testScene()
+	invalid := []raytracing.RenderOptions{
+		{ResolutionX: -1},
+		{ResolutionY: 1},
+		{SamplesPerPixel: -1},
+		{MaxBounces: -2},
+		{Gamma: -1},
+		{TMin: 1, TMax: 0.5},
+		// the default TMin exceeds TMax
+		{TMax: 0.0005},
+		{Workers: -1},
+		{Sampler: raytracing.SobolSampler + 1},
+		{AdaptiveThreshold: -0.1},
+		{MinSamples: -1},
+	}
+
+	for _, options := range invalid {
+		err := raytracing.Render(context.Background(), scene, camera, options, func(x, y int, c color.RGBA) {
+			t.Fatalf("%+v: expected nothing to be drawn", options)
+		})
+		if err == nil {
+			t.Errorf("%+v: expected error", options)
+		}
+	}
+}
+
+func TestRenderBackgroundOverride(t *testing.T) {
+	scene, camera := testScene()
+	options := raytracing.RenderOptions{
+		ResolutionX:     4,
+		ResolutionY:     4,
+		SamplesPerPixel: 1,
+		Background:      raytracing.SolidBackground(raytracing.Color{R: 1}),
+	}
+	camera.LookAt = Vec{Z: 1}
+
+	err := raytracing.Render(context.Background(), scene, camera, options, func(x, y int, c color.RGBA) {
+		if c.R != 255 || c.G != 0 || c.B != 0 {
+			t.Fatalf("expected red background, got %v", c)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRenderBackgroundOverrideDropsDistantLights(t *testing.T) {
+	scene, camera := testScene()
+	scene.Lights = []raytracing.Light{
+		raytracing.DirectionalLight{Direction: Vec{Z: -1}, Color: raytracing.White, Intensity: 5},
+	}
+	options := raytracing.RenderOptions{
+		ResolutionX:     8,
+		ResolutionY:     8,
+		SamplesPerPixel: 1,
+		Background:      raytracing.SolidBackground(raytracing.Black),
+	}
+
+	// only the replaced background lit the sphere
+	err := raytracing.Render(context.Background(), scene, camera, options, func(x, y int, c color.RGBA) {
+		if c != (color.RGBA{}) {
+			t.Fatalf("expected black image, got %v at %d, %d", c, x, y)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRenderDeterministic(t *testing.T) {
+	scene, camera := testScene()
+	scene.World = raytracing.World{Objects: []raytracing.Hittable{
+		raytracing.Sphere{Center: Vec{Z: -1}, Radius: 0.5, Material: raytracing.Dielectric(1.5)},
+		raytracing.Sphere{Center: Vec{Y: -100.5, Z: -1}, Radius: 100, Material: raytracing.Metal(raytracing.Color{R: 0.8, G: 0.8, B: 0.8}, 0.3)},
+		raytracing.NewConstantMedium(raytracing.Sphere{Center: Vec{X: 1, Z: -1}, Radius: 0.4}, 2, raytracing.White),
+	}}
+	camera.Aperture = 0.1
+	camera.AutoFocus = true
+
+	render := func(workers int, seed uint64, sampler raytracing.SamplerType) map[[2]int]color.RGBA {
+		image := make(map[[2]int]color.RGBA)
+		options := raytracing.RenderOptions{ResolutionX: 40, ResolutionY: 30, SamplesPerPixel: 4, Workers: workers, Seed: seed, Sampler: sampler}
+		raytracing.Render(context.Background(), scene, camera, options, func(x, y int, c color.RGBA) {
+			image[[2]int{x, y}] = c
+		})
+		return image
+	}
+
+	samplers := []raytracing.SamplerType{raytracing.IndependentSampler, raytracing.StratifiedSampler, raytracing.HaltonSampler, raytracing.SobolSampler}
+	for _, sampler := range samplers {
+		single := render(1, 42, sampler)
+		parallel := render(8, 42, sampler)
+		for pixel, c := range single {
+			if parallel[pixel] != c {
+				t.Fatalf("%v: expected identical images, pixel %v differs: %v != %v", sampler, pixel, c, parallel[pixel])
+			}
+		}
+	}
+
+	single := render(1, 42, raytracing.IndependentSampler)
+
+	differences := 0
+	for pixel, c := range render(8, 43, raytracing.IndependentSampler) {
+		if single[pixel] != c {
+			differences++
+		}
+	}
+	if differences == 0 {
+		t.Error("expected different seeds to give different images")
+	}
+}
+
+func TestRenderAdaptive(t *testing.T) {
+	scene, camera := testScene()
+	scene.Background = raytracing.SolidBackground(raytracing.Color{R: 0.5, G: 0.7, B: 1})
+	// the ground shadows part of the sky, so the sphere is noisy
+	scene.World = raytracing.World{Objects: []raytracing.Hittable{
+		raytracing.Sphere{Center: Vec{Z: -1}, Radius: 0.5, Material: raytracing.Lambertian(raytracing.Color{R: 0.5, G: 0.5, B: 0.5})},
+		raytracing.Sphere{Center: Vec{Y: -100.5, Z: -1}, Radius: 100, Material: raytracing.Lambertian(raytracing.Color{R: 0.5, G: 0.5, B: 0.5})},
+	}}
+	options := raytracing.RenderOptions{
+		ResolutionX:       40,
+		ResolutionY:       40,
+		SamplesPerPixel:   64,
+		MinSamples:        4,
+		AdaptiveThreshold: 0.05,
+	}
+
+	heat := make(map[[2]int]color.RGBA)
+	options.Heatmap = func(x, y int, c color.RGBA) {
+		heat[[2]int{x, y}] = c
+	}
+	err := raytracing.Render(context.Background(), scene, camera, options, func(x, y int, c color.RGBA) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(heat) != options.ResolutionX*options.ResolutionY {
+		t.Fatalf("expected heatmap of every pixel, got %d", len(heat))
+	}
+
+	// the flat background stops at the minimum, the sphere needs more
+	corner := heat[[2]int{0, 1}]
+	center := heat[[2]int{20, 20}]
+	if corner.R >= center.R || corner.G > center.G {
+		t.Errorf("expected more samples in the center %v than in the corner %v", center, corner)
+	}
+	if corner.R > 0x40 {
+		t.Errorf("expected the background to stop after few samples, got %v", corner)
+	}
+}
@@ -0,0 +1,37 @@
+package raytracing
+
+// RNG is a small and fast pseudo random number generator (splitmix64) and
+// the independent Sampler. Every sample of every pixel gets its own
+// generator, so images don't depend on how the work is scheduled.
+type RNG struct {
+	state uint64
+}
+
+func NewRNG(seed uint64) *RNG {
+	return &RNG{state: seed}
+}
+
+// sampleRNG returns the generator for sample s of pixel (x, y)
+func sampleRNG(seed uint64, x, y, s int) *RNG {
+	return NewRNG(mix64(seed ^ mix64(uint64(x)^mix64(uint64(y)^mix64(uint64(s))))))
+}
+
+// mix64 is the splitmix64 finalizer
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+func (r *RNG) Uint64() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+	return mix64(r.state)
+}
+
+// Float64 returns a uniformly distributed number in [0, 1)
+func (r *RNG) Float64() float64 {
+	return float64(r.Uint64()>>11) / (1 << 53)
+}
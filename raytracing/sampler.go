@@ -0,0 +1,206 @@
+package raytracing
+
+import (
+	"fmt"
+	"math"
+)
+
+// Sampler provides the random numbers of one sample of a pixel. Each call
+// returns the next dimension of the sample, samplers other than the
+// independent one distribute every dimension evenly over the samples of a
+// pixel.
+type Sampler interface {
+	// Float64 returns the next dimension in [0, 1)
+	Float64() float64
+}
+
+type SamplerType int
+
+const (
+	// IndependentSampler uses uncorrelated pseudo random numbers
+	IndependentSampler SamplerType = iota
+	// StratifiedSampler jitters every dimension within randomly permuted
+	// strata (latin hypercube sampling)
+	StratifiedSampler
+	// HaltonSampler uses the Halton sequence, randomly shifted per pixel
+	HaltonSampler
+	// SobolSampler uses the Owen scrambled Sobol sequence, padded with
+	// shuffled copies of its first two dimensions
+	SobolSampler
+)
+
+func (t SamplerType) String() string {
+	switch t {
+	case IndependentSampler:
+		return "independent"
+	case StratifiedSampler:
+		return "stratified"
+	case HaltonSampler:
+		return "halton"
+	case SobolSampler:
+		return "sobol"
+	}
+	return fmt.Sprintf("SamplerType(%d)", int(t))
+}
+
+// NewSampler returns the sampler for sample index of count samples of
+// pixel (x, y)
+func NewSampler(t SamplerType, seed uint64, x, y, index, count int) Sampler {
+	rng := sampleRNG(seed, x, y, index)
+	if t == IndependentSampler {
+		return rng
+	}
+
+	return &sequenceSampler{
+		kind:  t,
+		pixel: mix64(seed ^ mix64(uint64(x)^mix64(uint64(y)))),
+		index: index,
+		count: count,
+		rng:   rng,
+	}
+}
+
+// sampleIndex picks one of n elements with one dimension of sampler
+func sampleIndex(sampler Sampler, n int) int {
+	i := int(sampler.Float64() * float64(n))
+	if i >= n {
+		i = n - 1
+	}
+	return i
+}
+
+type sequenceSampler struct {
+	kind SamplerType
+	// pixel seeds the randomization, which is the same for all samples of
+	// a pixel
+	pixel     uint64
+	index     int
+	count     int
+	dimension int
+	// rng jitters within strata and fills dimensions the sequences lack
+	rng *RNG
+}
+
+func (s *sequenceSampler) Float64() float64 {
+	dimension := s.dimension
+	s.dimension++
+
+	var value float64
+	switch s.kind {
+	case StratifiedSampler:
+		stratum := permute(uint32(s.index), uint32(s.count), uint32(mix64(s.pixel^uint64(dimension))))
+		value = (float64(stratum) + s.rng.Float64()) / float64(s.count)
+	case HaltonSampler:
+		if dimension >= len(primes) {
+			return s.rng.Float64()
+		}
+		// Cranley-Patterson rotation
+		shift := float64(mix64(s.pixel^uint64(dimension))>>11) / (1 << 53)
+		value = radicalInverse(primes[dimension], uint64(s.index)) + shift
+		value -= math.Floor(value)
+	case SobolSampler:
+		// pairs of dimensions share the index shuffle to stay stratified in 2D
+		pairSeed := uint32(mix64(s.pixel ^ uint64(dimension/2)))
+		index := nestedUniformScramble(uint32(s.index), pairSeed)
+		bits := nestedUniformScramble(sobol(index, dimension%2), uint32(mix64(s.pixel^uint64(dimension)^0x5bd1e995)))
+		value = float64(bits) / (1 << 32)
+	default:
+		return s.rng.Float64()
+	}
+
+	// guard against rounding up to one
+	return math.Min(value, 1-1.0/(1<<53))
+}
+
+var primes = []uint64{
+	2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47, 53,
+	59, 61, 67, 71, 73, 79, 83, 89, 97, 101, 103, 107, 109, 113, 127, 131,
+	137, 139, 149, 151, 157, 163, 167, 173, 179, 181, 191, 193, 197, 199, 211, 223,
+	227, 229, 233, 239, 241, 251, 257, 263, 269, 271, 277, 281, 283, 293, 307, 311,
+}
+
+// radicalInverse mirrors the digits of i in base at the decimal point
+func radicalInverse(base, i uint64) float64 {
+	inverseBase := 1 / float64(base)
+	factor := inverseBase
+	value := 0.0
+	for i > 0 {
+		value += float64(i%base) * factor
+		i /= base
+		factor *= inverseBase
+	}
+	return value
+}
+
+// sobol returns the first (dimension 0) or second dimension of the Sobol
+// sequence as a 32 bit fraction
+func sobol(index uint32, dimension int) uint32 {
+	if dimension == 0 {
+		return reverseBits(index)
+	}
+
+	result := uint32(0)
+	for v := uint32(1 << 31); index != 0; index >>= 1 {
+		if index&1 != 0 {
+			result ^= v
+		}
+		v ^= v >> 1
+	}
+	return result
+}
+
+func reverseBits(x uint32) uint32 {
+	x = x<<16 | x>>16
+	x = (x&0x00ff00ff)<<8 | (x&0xff00ff00)>>8
+	x = (x&0x0f0f0f0f)<<4 | (x&0xf0f0f0f0)>>4
+	x = (x&0x33333333)<<2 | (x&0xcccccccc)>>2
+	x = (x&0x55555555)<<1 | (x&0xaaaaaaaa)>>1
+	return x
+}
+
+// nestedUniformScramble is a hash based Owen scramble (Burley 2020)
+func nestedUniformScramble(x, seed uint32) uint32 {
+	x = reverseBits(x)
+	x += seed
+	x ^= x * 0x6c50b47c
+	x ^= x * 0xb82f1e52
+	x ^= x * 0xc7afe638
+	x ^= x * 0x8d22f6e6
+	return reverseBits(x)
+}
+
+// permute returns element i of a random permutation of [0, n) selected by
+// seed (Kensler 2013)
+func permute(i, n, seed uint32) uint32 {
+	w := n - 1
+	w |= w >> 1
+	w |= w >> 2
+	w |= w >> 4
+	w |= w >> 8
+	w |= w >> 16
+
+	for {
+		i ^= seed
+		i *= 0xe170893d
+		i ^= seed >> 16
+		i ^= (i & w) >> 4
+		i ^= seed >> 8
+		i *= 0x0929eb3f
+		i ^= seed >> 23
+		i ^= (i & w) >> 1
+		i *= 1 | seed>>27
+		i *= 0x6935fa69
+		i ^= (i & w) >> 11
+		i *= 0x74dcb303
+		i ^= (i & w) >> 2
+		i *= 0x9e501cc3
+		i ^= (i & w) >> 2
+		i *= 0xc860a3df
+		i &= w
+		i ^= i >> 5
+		if i < n {
+			break
+		}
+	}
+	return (i + seed) % n
+}
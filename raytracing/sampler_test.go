@@ -0,0 +1,75 @@
+package raytracing_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func TestSamplersStratify(t *testing.T) {
+	const count = 16
+
+	tests := []struct {
+		Type       raytracing.SamplerType
+		Dimensions int
+	}{
+		{raytracing.StratifiedSampler, 8},
+		{raytracing.HaltonSampler, 1},
+		{raytracing.SobolSampler, 8},
+	}
+
+	for _, test := range tests {
+		for _, pixel := range [][2]int{{0, 0}, {13, 7}} {
+			// every sample of a pixel falls into a different stratum
+			strata := make([]map[int]bool, test.Dimensions)
+			for d := range strata {
+				strata[d] = make(map[int]bool)
+			}
+
+			for i := 0; i < count; i++ {
+				sampler := raytracing.NewSampler(test.Type, 42, pixel[0], pixel[1], i, count)
+				for d := 0; d < test.Dimensions; d++ {
+					value := sampler.Float64()
+					if value < 0 || value >= 1 {
+						t.Fatalf("%v: value %v out of range", test.Type, value)
+					}
+					strata[d][int(value*count)] = true
+				}
+			}
+
+			for d, s := range strata {
+				if len(s) != count {
+					t.Errorf("%v: dimension %d covers %d of %d strata", test.Type, d, len(s), count)
+				}
+			}
+		}
+	}
+}
+
+func TestSamplersConverge(t *testing.T) {
+	// estimate the integral of x·y over the unit square, which is 1/4
+	const count = 64
+	const pixels = 50
+
+	squaredError := func(samplerType raytracing.SamplerType) float64 {
+		sum := 0.0
+		for p := 0; p < pixels; p++ {
+			estimate := 0.0
+			for i := 0; i < count; i++ {
+				sampler := raytracing.NewSampler(samplerType, 1, p, 0, i, count)
+				estimate += sampler.Float64() * sampler.Float64()
+			}
+			estimate /= count
+			sum += (estimate - 0.25) * (estimate - 0.25)
+		}
+		return sum / pixels
+	}
+
+	independent := squaredError(raytracing.IndependentSampler)
+	for _, samplerType := range []raytracing.SamplerType{raytracing.StratifiedSampler, raytracing.HaltonSampler, raytracing.SobolSampler} {
+		if e := squaredError(samplerType); e >= independent || math.IsNaN(e) {
+			t.Errorf("%v: expected lower error than independent sampling %v, got %v", samplerType, independent, e)
+		}
+	}
+}
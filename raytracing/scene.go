@@ -0,0 +1,50 @@
+package raytracing
+
+// Scene is everything Render needs besides the camera
+type Scene struct {
+	World Hittable
+	// Background is the radiance of rays leaving the scene, nil is black
+	Background Background
+	// Lights are sampled explicitly at diffuse surfaces, area lights must be
+	// part of World as well
+	Lights []Light
+}
+
+// Background returns the radiance coming from a direction
+type Background func(direction Vec) Color
+
+func (s Scene) background(direction Vec) Color {
+	if s.Background == nil {
+		return Black
+	}
+	return s.Background(direction)
+}
+
+// lightPDF is the density of choosing direction when sampling a
+// random light
+func (s Scene) lightPDF(point, direction Vec) float64 {
+	if len(s.Lights) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, light := range s.Lights {
+		sum += light.PDF(point, direction)
+	}
+	return sum / float64(len(s.Lights))
+}
+
+func SolidBackground(color Color) Background {
+	return func(direction Vec) Color {
+		return color
+	}
+}
+
+// SkyGradient blends from white at the horizon to light blue at the zenith
+func SkyGradient() Background {
+	return func(direction Vec) Color {
+		t := 0.5 * (direction.Normalized().Y + 1)
+
+		return Color{1, 1, 1}.Multiply(1 - t).Add(Color{0.5, 0.7, 1.0}.Multiply(t))
+	}
+}
@@ -0,0 +1,180 @@
+package raytracing
+
+import (
+	"math"
+	"time"
+)
+
+// sunAngularRadius is the apparent radius of the sun seen from earth
+const sunAngularRadius = 0.00465
+
+// skyScale maps the Preetham luminance in kcd/m² to radiance values
+// around one
+const skyScale = 1.0 / 15
+
+// Sky is the Preetham analytic daylight model with a sun disk. Y is up,
+// north points along -Z and east along +X. Use its Background method as
+// the scene background and add Sun to the lights.
+type Sky struct {
+	// SunElevation above the horizon and SunAzimuth clockwise from north,
+	// both in radians
+	SunElevation float64
+	SunAzimuth   float64
+	// Turbidity describes the haze, from 2 (clear) to 10 (hazy)
+	Turbidity float64
+
+	Intensity    float64
+	SunIntensity float64
+	// Ground is the radiance below the horizon
+	Ground Color
+}
+
+func NewSky(elevation, azimuth, turbidity float64) Sky {
+	return Sky{
+		SunElevation: elevation,
+		SunAzimuth:   azimuth,
+		Turbidity:    turbidity,
+		Intensity:    1,
+		SunIntensity: 3,
+		Ground:       Color{0.2, 0.2, 0.2},
+	}
+}
+
+// SunDirection points towards the sun
+func (s Sky) SunDirection() Vec {
+	return Vec{
+		X: math.Sin(s.SunAzimuth) * math.Cos(s.SunElevation),
+		Y: math.Sin(s.SunElevation),
+		Z: -math.Cos(s.SunAzimuth) * math.Cos(s.SunElevation),
+	}
+}
+
+// Sun is the light of the sun disk, reddened by the air mass it shines
+// through
+func (s Sky) Sun() DirectionalLight {
+	// Kasten-Young air mass
+	zenith := math.Pi/2 - s.SunElevation
+	zenithDegrees := math.Min(zenith*180/math.Pi, 93.8)
+	airMass := 1 / (math.Cos(zenith) + 0.50572*math.Pow(96.07995-zenithDegrees, -1.6364))
+
+	// rough optical depth per channel, growing with turbidity
+	depth := Color{0.03, 0.06, 0.12}.Multiply(s.Turbidity / 2)
+	color := Color{
+		R: math.Exp(-depth.R * airMass),
+		G: math.Exp(-depth.G * airMass),
+		B: math.Exp(-depth.B * airMass),
+	}
+	if s.SunElevation < 0 {
+		color = Black
+	}
+
+	return DirectionalLight{
+		Direction:     s.SunDirection().Multiply(-1),
+		Color:         color,
+		Intensity:     s.SunIntensity,
+		AngularRadius: sunAngularRadius,
+	}
+}
+
+func (s Sky) Background(direction Vec) Color {
+	direction = direction.Normalized()
+	if direction.Y < 0 {
+		return s.Ground
+	}
+
+	return s.sky(direction).Add(s.Sun().Radiance(direction))
+}
+
+// perez is the Perez sky luminance distribution for the angle to the
+// zenith theta and the angle to the sun gamma
+func perez(c [5]float64, theta, gamma float64) float64 {
+	cosTheta := math.Max(math.Cos(theta), 0.01)
+	cosGamma := math.Cos(gamma)
+	return (1 + c[0]*math.Exp(c[1]/cosTheta)) * (1 + c[2]*math.Exp(c[3]*gamma) + c[4]*cosGamma*cosGamma)
+}
+
+func (s Sky) sky(direction Vec) Color {
+	t := s.Turbidity
+	// the model is only valid for the sun above the horizon
+	thetaSun := math.Min(math.Pi/2-s.SunElevation, math.Pi/2-0.01)
+	theta := math.Acos(math.Min(direction.Y, 1))
+	gamma := math.Acos(math.Max(-1, math.Min(1, direction.Dot(s.SunDirection()))))
+
+	chi := (4.0/9 - t/120) * (math.Pi - 2*thetaSun)
+	zenithY := (4.0453*t-4.9710)*math.Tan(chi) - 0.2155*t + 2.4192
+
+	th := [4]float64{thetaSun * thetaSun * thetaSun, thetaSun * thetaSun, thetaSun, 1}
+	polynomial := func(t2, t1, t0 [4]float64) float64 {
+		sum := 0.0
+		for i := range th {
+			sum += (t*t*t2[i] + t*t1[i] + t0[i]) * th[i]
+		}
+		return sum
+	}
+	zenithX := polynomial(
+		[4]float64{0.00166, -0.00375, 0.00209, 0},
+		[4]float64{-0.02903, 0.06377, -0.03202, 0.00394},
+		[4]float64{0.11693, -0.21196, 0.06052, 0.25886},
+	)
+	zenithYChroma := polynomial(
+		[4]float64{0.00275, -0.00610, 0.00317, 0},
+		[4]float64{-0.04214, 0.08970, -0.04153, 0.00516},
+		[4]float64{0.15346, -0.26756, 0.06670, 0.26688},
+	)
+
+	coefficientsY := [5]float64{0.1787*t - 1.4630, -0.3554*t + 0.4275, -0.0227*t + 5.3251, 0.1206*t - 2.5771, -0.0670*t + 0.3703}
+	coefficientsX := [5]float64{-0.0193*t - 0.2592, -0.0665*t + 0.0008, -0.0004*t + 0.2125, -0.0641*t - 0.8989, -0.0033*t + 0.0452}
+	coefficientsYChroma := [5]float64{-0.0167*t - 0.2608, -0.0950*t + 0.0092, -0.0079*t + 0.2102, -0.0441*t - 1.6537, -0.0109*t + 0.0529}
+
+	relative := func(zenith float64, c [5]float64) float64 {
+		return zenith * perez(c, theta, gamma) / perez(c, 0, thetaSun)
+	}
+	luminance := relative(zenithY, coefficientsY)
+	x := relative(zenithX, coefficientsX)
+	y := relative(zenithYChroma, coefficientsYChroma)
+
+	// xyY to XYZ to linear sRGB
+	X := x / y * luminance
+	Z := (1 - x - y) / y * luminance
+	rgb := Color{
+		R: math.Max(0, 3.2406*X-1.5372*luminance-0.4986*Z),
+		G: math.Max(0, -0.9689*X+1.8758*luminance+0.0415*Z),
+		B: math.Max(0, 0.0557*X-0.2040*luminance+1.0570*Z),
+	}
+
+	// fade out the sky as the sun sets
+	fade := math.Max(0, math.Min(1, 1+s.SunElevation*10))
+	return rgb.Multiply(skyScale * s.Intensity * fade)
+}
+
+// SunPosition returns the elevation and azimuth (clockwise from north) of
+// the sun in radians at a time and a location given in degrees, following
+// the NOAA approximation
+func SunPosition(t time.Time, latitude, longitude float64) (elevation, azimuth float64) {
+	t = t.UTC()
+	hours := float64(t.Hour()) + float64(t.Minute())/60 + float64(t.Second())/3600
+
+	// fractional year
+	g := 2 * math.Pi / 365 * (float64(t.YearDay()-1) + (hours-12)/24)
+
+	equationOfTime := 229.18 * (0.000075 + 0.001868*math.Cos(g) - 0.032077*math.Sin(g) -
+		0.014615*math.Cos(2*g) - 0.040849*math.Sin(2*g))
+	declination := 0.006918 - 0.399912*math.Cos(g) + 0.070257*math.Sin(g) -
+		0.006758*math.Cos(2*g) + 0.000907*math.Sin(2*g) -
+		0.002697*math.Cos(3*g) + 0.00148*math.Sin(3*g)
+
+	// true solar time in minutes and the hour angle
+	solarTime := hours*60 + equationOfTime + 4*longitude
+	hourAngle := (solarTime/4 - 180) * math.Pi / 180
+	lat := latitude * math.Pi / 180
+
+	sinElevation := math.Sin(lat)*math.Sin(declination) + math.Cos(lat)*math.Cos(declination)*math.Cos(hourAngle)
+	elevation = math.Asin(math.Max(-1, math.Min(1, sinElevation)))
+
+	azimuth = math.Atan2(-math.Sin(hourAngle), math.Tan(declination)*math.Cos(lat)-math.Sin(lat)*math.Cos(hourAngle))
+	if azimuth < 0 {
+		azimuth += 2 * math.Pi
+	}
+
+	return elevation, azimuth
+}
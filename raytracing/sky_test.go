@@ -0,0 +1,56 @@
+package raytracing_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func TestSunPosition(t *testing.T) {
+	degrees := func(radians float64) float64 { return radians * 180 / math.Pi }
+
+	// around local solar noon at the summer solstice in Berlin
+	elevation, azimuth := raytracing.SunPosition(time.Date(2024, 6, 21, 11, 8, 0, 0, time.UTC), 52.5, 13.4)
+	if math.Abs(degrees(elevation)-60.9) > 1 || math.Abs(degrees(azimuth)-180) > 2 {
+		t.Errorf("expected sun high in the south, got elevation %.1f° azimuth %.1f°", degrees(elevation), degrees(azimuth))
+	}
+
+	// morning sun in the east, night below the horizon
+	_, azimuth = raytracing.SunPosition(time.Date(2024, 3, 20, 6, 0, 0, 0, time.UTC), 0, 0)
+	if math.Abs(degrees(azimuth)-90) > 2 {
+		t.Errorf("expected equinox sunrise in the east, got azimuth %.1f°", degrees(azimuth))
+	}
+	elevation, _ = raytracing.SunPosition(time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC), 0, 0)
+	if elevation > 0 {
+		t.Errorf("expected sun below the horizon at midnight, got %.1f°", degrees(elevation))
+	}
+}
+
+func TestSky(t *testing.T) {
+	sky := raytracing.NewSky(math.Pi/4, math.Pi, 3)
+	sun := sky.Sun()
+	rng := raytracing.NewRNG(1)
+
+	zenith := sky.Background(Vec{Y: 1})
+	if zenith.B <= zenith.R || zenith.R <= 0 {
+		t.Errorf("expected blue sky at the zenith, got %v", zenith)
+	}
+	if ground := sky.Background(Vec{Y: -1}); ground != sky.Ground {
+		t.Errorf("expected ground color below the horizon, got %v", ground)
+	}
+
+	// the sun disk is brighter than the sky around it and can be sampled
+	sunDirection := sky.SunDirection()
+	if disk := sky.Background(sunDirection); disk.R < 100*zenith.R {
+		t.Errorf("expected bright sun disk, got %v", disk)
+	}
+	for i := 0; i < 100; i++ {
+		sample := sun.Sample(Vec{}, rng)
+		requireEqual(t, sun.PDF(Vec{}, sample.Direction), sample.PDF)
+		if sample.Direction.Dot(sunDirection) < math.Cos(0.005) {
+			t.Fatalf("expected sample within the sun disk, got %v", sample.Direction)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package raytracing
+
+import "math"
+
+type StereoLayout int
+
+const (
+	// SideBySide puts the left eye into the left half of the image
+	SideBySide StereoLayout = iota
+	// OverUnder puts the left eye into the upper half of the image
+	OverUnder
+)
+
+// Stereo renders a left and a right eye view into one image, each eye
+// using Projection
+type Stereo struct {
+	Projection Projection
+	Layout     StereoLayout
+
+	// InterocularDistance between the eyes, in scene units
+	InterocularDistance float64
+	// Convergence is the distance at which the views of both eyes meet,
+	// zero keeps them parallel
+	Convergence float64
+	// Omnidirectional offsets the eyes perpendicular to each ray instead
+	// of along the camera's X axis, as needed for 360° panoramas
+	Omnidirectional bool
+}
+
+// NewStereoCamera turns camera into a stereo rig, keeping its projection
+func NewStereoCamera(camera Camera, interocularDistance, convergence float64, layout StereoLayout) Camera {
+	camera.Projection = Stereo{
+		Projection:          camera.projection(),
+		Layout:              layout,
+		InterocularDistance: interocularDistance,
+		Convergence:         convergence,
+	}
+	return camera
+}
+
+// NewODSCamera turns camera into an omni-directional stereo rig rendering
+// equirectangular panoramas for VR viewers
+func NewODSCamera(camera Camera, interocularDistance float64, layout StereoLayout) Camera {
+	camera.Projection = Stereo{
+		Projection:          Equirectangular{},
+		Layout:              layout,
+		InterocularDistance: interocularDistance,
+		Omnidirectional:     true,
+	}
+	return camera
+}
+
+func (p Stereo) Project(s, t, aspectRatio float64) (origin, direction Vec) {
+	// which eye, and the coordinates within its half of the image
+	eye := -1.0
+	switch p.Layout {
+	case OverUnder:
+		aspectRatio *= 2
+		if t >= 0.5 {
+			t = 2*t - 1
+		} else {
+			eye, t = 1, 2*t
+		}
+	default:
+		aspectRatio /= 2
+		if s < 0.5 {
+			s = 2 * s
+		} else {
+			eye, s = 1, 2*s-1
+		}
+	}
+
+	origin, direction = p.Projection.Project(s, t, aspectRatio)
+	if direction == (Vec{}) {
+		return origin, direction
+	}
+
+	offset := Vec{X: 1}
+	if p.Omnidirectional {
+		// perpendicular to the horizontal part of the direction
+		horizontal := math.Hypot(direction.X, direction.Z)
+		if horizontal == 0 {
+			offset = Vec{}
+		} else {
+			offset = Vec{X: -direction.Z / horizontal, Z: direction.X / horizontal}
+		}
+	}
+	eyeOrigin := origin.Add(offset.Multiply(eye * p.InterocularDistance / 2))
+
+	if p.Convergence > 0 {
+		// aim at the point the centered ray reaches at the convergence distance
+		target := origin.Add(direction.Multiply(p.Convergence / direction.Length()))
+		if !p.Omnidirectional && direction.Z < 0 {
+			// converge on a plane to keep the views' vertical parallax zero
+			target = origin.Add(direction.Multiply(p.Convergence / -direction.Z))
+		}
+		direction = target.Subtract(eyeOrigin)
+	}
+
+	return eyeOrigin, direction
+}
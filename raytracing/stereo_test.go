@@ -0,0 +1,58 @@
+package raytracing_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func TestStereoConvergence(t *testing.T) {
+	camera := raytracing.Camera{Up: Vec{Y: 1}, From: Vec{Z: 5}, LookAt: Vec{}, Zoom: 1}
+
+	layouts := []struct {
+		Layout      raytracing.StereoLayout
+		Left, Right [2]float64
+	}{
+		{raytracing.SideBySide, [2]float64{0.25, 0.5}, [2]float64{0.75, 0.5}},
+		{raytracing.OverUnder, [2]float64{0.5, 0.75}, [2]float64{0.5, 0.25}},
+	}
+
+	for _, layout := range layouts {
+		stereo := raytracing.NewStereoCamera(camera, 0.1, 5, layout.Layout)
+		rayCaster := stereo.RayCaster(1)
+
+		left := rayCaster(layout.Left[0], layout.Left[1], raytracing.NewRNG(1))
+		right := rayCaster(layout.Right[0], layout.Right[1], raytracing.NewRNG(1))
+
+		requireEqual(t, left.Origin.X, -0.05)
+		requireEqual(t, right.Origin.X, 0.05)
+
+		// both eyes look at the convergence point
+		for _, ray := range []raytracing.Ray{left, right} {
+			atOrigin := ray.Origin.Add(ray.Direction.Multiply(-ray.Origin.Z / ray.Direction.Z))
+			requireEqual(t, atOrigin.Length(), 0)
+		}
+	}
+}
+
+func TestODS(t *testing.T) {
+	camera := raytracing.NewODSCamera(raytracing.Camera{Up: Vec{Y: 1}, From: Vec{}, LookAt: Vec{Z: -1}}, 0.1, raytracing.OverUnder)
+	rayCaster := camera.RayCaster(1)
+
+	for i := 0; i <= 16; i++ {
+		s := float64(i) / 16
+		for _, t0 := range []float64{0.6, 0.9, 0.1, 0.4} {
+			ray := rayCaster(s, t0, raytracing.NewRNG(1))
+
+			// eyes sit on a circle, perpendicular to the viewing direction
+			requireEqual(t, ray.Origin.Length(), 0.05)
+			requireEqual(t, ray.Origin.Dot(ray.Direction), 0)
+		}
+	}
+
+	// looking forward, the left eye sits to the left
+	if left := rayCaster(0.5, 0.75, raytracing.NewRNG(1)); math.Abs(left.Origin.X+0.05) > 1e-9 {
+		t.Errorf("expected left eye at x=-0.05, got %v", left.Origin)
+	}
+}
@@ -0,0 +1,95 @@
+package raytracing
+
+import (
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+)
+
+// Texture is evaluated at the surface coordinates (u, v) and the hit point
+type Texture interface {
+	Value(u, v float64, point Vec) Color
+}
+
+type SolidColor Color
+
+func (c SolidColor) Value(u, v float64, point Vec) Color {
+	return Color(c)
+}
+
+// CheckerTexture alternates between two textures in a 3D grid of cubes
+// with the edge length Scale
+type CheckerTexture struct {
+	Odd   Texture
+	Even  Texture
+	Scale float64
+}
+
+func (c CheckerTexture) Value(u, v float64, point Vec) Color {
+	scale := c.Scale
+	if scale == 0 {
+		scale = 1
+	}
+
+	sum := int(math.Floor(point.X/scale)) + int(math.Floor(point.Y/scale)) + int(math.Floor(point.Z/scale))
+	if sum%2 == 0 {
+		return c.Even.Value(u, v, point)
+	}
+	return c.Odd.Value(u, v, point)
+}
+
+// ImageTexture maps an image onto (u, v), repeating it outside of [0, 1]
+type ImageTexture struct {
+	Image image.Image
+}
+
+func LoadImageTexture(path string) (ImageTexture, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ImageTexture{}, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return ImageTexture{}, err
+	}
+
+	return ImageTexture{Image: img}, nil
+}
+
+func (t ImageTexture) Value(u, v float64, point Vec) Color {
+	bounds := t.Image.Bounds()
+	if bounds.Empty() {
+		return Color{R: 0, G: 1, B: 1}
+	}
+
+	// v runs upwards, image rows downwards
+	u = u - math.Floor(u)
+	v = 1 - (v - math.Floor(v))
+
+	x := bounds.Min.X + int(u*float64(bounds.Dx()))
+	y := bounds.Min.Y + int(v*float64(bounds.Dy()))
+	if x >= bounds.Max.X {
+		x = bounds.Max.X - 1
+	}
+	if y >= bounds.Max.Y {
+		y = bounds.Max.Y - 1
+	}
+
+	r, g, b, _ := t.Image.At(x, y).RGBA()
+
+	return Color{R: srgbToLinear(r), G: srgbToLinear(g), B: srgbToLinear(b)}
+}
+
+// srgbToLinear decodes a 16 bit color channel, images are assumed to be
+// sRGB encoded regardless of the gamma the render is displayed with
+func srgbToLinear(c uint32) float64 {
+	value := float64(c) / 0xffff
+	if value <= 0.04045 {
+		return value / 12.92
+	}
+	return math.Pow((value+0.055)/1.055, 2.4)
+}
@@ -0,0 +1,73 @@
+package raytracing_test
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func TestCheckerTexture(t *testing.T) {
+	black := raytracing.SolidColor{}
+	white := raytracing.SolidColor{R: 1, G: 1, B: 1}
+	checker := raytracing.CheckerTexture{Odd: black, Even: white, Scale: 0.5}
+
+	tests := []struct {
+		Point    Vec
+		Expected raytracing.Color
+	}{
+		{Point: Vec{X: 0.1, Y: 0.1, Z: 0.1}, Expected: raytracing.Color(white)},
+		{Point: Vec{X: 0.6, Y: 0.1, Z: 0.1}, Expected: raytracing.Color(black)},
+		{Point: Vec{X: -0.1, Y: 0.1, Z: 0.1}, Expected: raytracing.Color(black)},
+		{Point: Vec{X: -0.1, Y: -0.1, Z: 0.1}, Expected: raytracing.Color(white)},
+	}
+
+	for _, test := range tests {
+		if actual := checker.Value(0, 0, test.Point); actual != test.Expected {
+			t.Errorf("point %v: expected %v, got %v", test.Point, test.Expected, actual)
+		}
+	}
+}
+
+func TestImageTexture(t *testing.T) {
+	// left column red, right column blue, top row brighter
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	img.Set(1, 0, color.RGBA{B: 255, A: 255})
+	img.Set(0, 1, color.RGBA{R: 128, A: 255})
+	img.Set(1, 1, color.RGBA{B: 128, A: 255})
+	texture := raytracing.ImageTexture{Image: img}
+
+	requireEqual(t, texture.Value(0.25, 0.75, Vec{}).R, 1)
+	requireEqual(t, texture.Value(0.75, 0.75, Vec{}).B, 1)
+	requireEqual(t, texture.Value(1.75, 1.75, Vec{}).B, 1)
+
+	// colors are decoded from sRGB to linear space
+	bottomLeft := texture.Value(0.25, 0.25, Vec{})
+	requireEqual(t, bottomLeft.R, math.Pow((128.0/255+0.055)/1.055, 2.4))
+}
+
+func TestSphereUV(t *testing.T) {
+	sphere := raytracing.Sphere{Radius: 2}
+
+	tests := []struct {
+		Origin Vec
+		U      float64
+		V      float64
+	}{
+		{Origin: Vec{X: 5}, U: 0.5, V: 0.5},
+		{Origin: Vec{Z: 5}, U: 0.25, V: 0.5},
+		{Origin: Vec{Y: 5}, U: 0.5, V: 1},
+	}
+
+	for _, test := range tests {
+		hit := sphere.Hit(raytracing.Ray{Origin: test.Origin, Direction: test.Origin.Multiply(-1)}, 0.001, 10000)
+		if hit == nil {
+			t.Fatalf("expected hit from %v", test.Origin)
+		}
+		requireEqual(t, hit.U, test.U)
+		requireEqual(t, hit.V, test.V)
+	}
+}
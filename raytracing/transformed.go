@@ -0,0 +1,52 @@
+package raytracing
+
+import "errors"
+
+// Transformed places an object with an affine transform. The object is not
+// copied, so the same mesh can be instanced many times.
+type Transformed struct {
+	Object    Hittable
+	Transform Matrix
+
+	inverse Matrix
+	normal  Matrix
+	box     AABB
+}
+
+// NewTransformed fails if the transform is not invertible, e.g. when it
+// scales an axis to zero
+func NewTransformed(object Hittable, transform Matrix) (*Transformed, error) {
+	inverse, ok := transform.Inverse()
+	if !ok {
+		return nil, errors.New("transform is not invertible")
+	}
+
+	return &Transformed{
+		Object:    object,
+		Transform: transform,
+		inverse:   inverse,
+		normal:    inverse.Transpose(),
+		box:       transform.TransformAABB(object.BoundingBox()),
+	}, nil
+}
+
+func (t *Transformed) Hit(ray Ray, tMin, tMax float64) *Hit {
+	// the direction is not normalized, so t is the same in both spaces
+	objectRay := ray
+	objectRay.Origin = t.inverse.TransformPoint(ray.Origin)
+	objectRay.Direction = t.inverse.TransformVector(ray.Direction)
+
+	hit := t.Object.Hit(objectRay, tMin, tMax)
+	if hit == nil {
+		return nil
+	}
+
+	hit.Point = t.Transform.TransformPoint(hit.Point)
+	hit.Normal = t.normal.TransformVector(hit.Normal).Normalized()
+
+	return hit
+}
+
+func (t *Transformed) BoundingBox() AABB {
+	return t.box
+}
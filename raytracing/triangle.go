@@ -0,0 +1,87 @@
+package raytracing
+
+import "math"
+
+type Triangle struct {
+	A        Vec
+	B        Vec
+	C        Vec
+	Material Material
+}
+
+// intersectTriangle implements the Möller–Trumbore algorithm, returning the
+// ray parameter t and the barycentric coordinates u and v of the hit
+func intersectTriangle(a, b, c Vec, ray Ray, tMin, tMax float64) (t, u, v float64, ok bool) {
+	edge1 := b.Subtract(a)
+	edge2 := c.Subtract(a)
+
+	pvec := ray.Direction.Cross(edge2)
+	det := edge1.Dot(pvec)
+
+	// ray parallel to triangle
+	if math.Abs(det) < 1e-12 {
+		return 0, 0, 0, false
+	}
+	invDet := 1 / det
+
+	tvec := ray.Origin.Subtract(a)
+	u = tvec.Dot(pvec) * invDet
+	if u < 0 || u > 1 {
+		return 0, 0, 0, false
+	}
+
+	qvec := tvec.Cross(edge1)
+	v = ray.Direction.Dot(qvec) * invDet
+	if v < 0 || u+v > 1 {
+		return 0, 0, 0, false
+	}
+
+	t = edge2.Dot(qvec) * invDet
+	if t < tMin || tMax < t {
+		return 0, 0, 0, false
+	}
+
+	return t, u, v, true
+}
+
+// triangleHit orients the geometric normal against the ray and the
+// (interpolated) shading normal to the same side
+func triangleHit(ray Ray, t float64, geometricNormal, shadingNormal Vec, u, v float64, material Material) *Hit {
+	frontFace := ray.Direction.Dot(geometricNormal) < 0
+
+	normal := shadingNormal
+	if normal.Dot(geometricNormal) < 0 {
+		normal = normal.Multiply(-1)
+	}
+	if !frontFace {
+		normal = normal.Multiply(-1)
+	}
+
+	return &Hit{
+		Point:     ray.At(t),
+		T:         t,
+		Normal:    normal,
+		Material:  material,
+		FrontFace: frontFace,
+		U:         u,
+		V:         v,
+	}
+}
+
+func (tr Triangle) Hit(ray Ray, tMin, tMax float64) *Hit {
+	t, u, v, ok := intersectTriangle(tr.A, tr.B, tr.C, ray, tMin, tMax)
+	if !ok {
+		return nil
+	}
+
+	// without texture coordinates the barycentric coordinates are used
+	normal := tr.B.Subtract(tr.A).Cross(tr.C.Subtract(tr.A)).Normalized()
+	return triangleHit(ray, t, normal, normal, u, v, tr.Material)
+}
+
+func (tr Triangle) BoundingBox() AABB {
+	return AABB{
+		Min: tr.A.Min(tr.B).Min(tr.C),
+		Max: tr.A.Max(tr.B).Max(tr.C),
+	}.Padded(1e-4)
+}
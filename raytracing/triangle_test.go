@@ -0,0 +1,97 @@
+package raytracing_test
+
+import (
+	"testing"
+
+	"github.com/davherrmann/rtgo/raytracing"
+)
+
+func TestTriangleHit(t *testing.T) {
+	triangle := raytracing.Triangle{
+		A: Vec{X: -1, Y: -1},
+		B: Vec{X: 1, Y: -1},
+		C: Vec{Y: 1},
+	}
+
+	tests := []struct {
+		Ray       raytracing.Ray
+		Hit       bool
+		T         float64
+		Normal    Vec
+		FrontFace bool
+	}{
+		{Ray: raytracing.Ray{Origin: Vec{Z: 2}, Direction: Vec{Z: -1}}, Hit: true, T: 2, Normal: Vec{Z: 1}, FrontFace: true},
+		{Ray: raytracing.Ray{Origin: Vec{Z: -1}, Direction: Vec{Z: 2}}, Hit: true, T: 0.5, Normal: Vec{Z: -1}, FrontFace: false},
+		{Ray: raytracing.Ray{Origin: Vec{X: 1, Y: 1, Z: 2}, Direction: Vec{Z: -1}}, Hit: false},
+		{Ray: raytracing.Ray{Origin: Vec{Z: 2}, Direction: Vec{X: 1}}, Hit: false},
+	}
+
+	for _, test := range tests {
+		hit := triangle.Hit(test.Ray, 0.001, 10000)
+		if (hit != nil) != test.Hit {
+			t.Fatalf("ray %#v: expected hit %v", test.Ray, test.Hit)
+		}
+		if hit == nil {
+			continue
+		}
+
+		requireEqual(t, hit.T, test.T)
+		requireEqual(t, hit.Normal, test.Normal)
+		if hit.FrontFace != test.FrontFace {
+			t.Errorf("ray %#v: expected front face %v", test.Ray, test.FrontFace)
+		}
+	}
+}
+
+func TestMeshSmoothNormals(t *testing.T) {
+	// unit quad in the XY plane, normals tilted towards the outside
+	mesh, err := raytracing.NewMesh(
+		[]Vec{{X: -1, Y: -1}, {X: 1, Y: -1}, {X: 1, Y: 1}, {X: -1, Y: 1}},
+		[]Vec{{X: -1, Z: 1}, {X: 1, Z: 1}, {X: 1, Z: 1}, {X: -1, Z: 1}},
+		nil,
+		[]int{0, 1, 2, 0, 2, 3},
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hit := mesh.Hit(raytracing.Ray{Origin: Vec{Z: 1}, Direction: Vec{Z: -1}}, 0.001, 10000)
+	if hit == nil {
+		t.Fatal("expected hit")
+	}
+	requireEqual(t, hit.Normal, Vec{Z: 1})
+
+	hit = mesh.Hit(raytracing.Ray{Origin: Vec{X: 1, Y: 0, Z: 1}, Direction: Vec{Z: -1}}, 0.001, 10000)
+	if hit == nil {
+		t.Fatal("expected hit")
+	}
+	requireEqual(t, hit.Normal, Vec{X: 1, Z: 1}.Normalized())
+}
+
+func TestMeshInvalid(t *testing.T) {
+	vertices := []Vec{{X: -1, Y: -1}, {X: 1, Y: -1}, {X: 1, Y: 1}}
+	tests := map[string]struct {
+		normals   []Vec
+		texCoords [][2]float64
+		indices   []int
+	}{
+		"index out of range": {indices: []int{0, 1, 3}},
+		"negative index":     {indices: []int{0, -1, 2}},
+		"partial triangle":   {indices: []int{0, 1}},
+		"no triangles":       {},
+		"missing normals":    {normals: []Vec{{Z: 1}}, indices: []int{0, 1, 2}},
+		"missing texcoords":  {texCoords: [][2]float64{{0, 0}, {1, 0}}, indices: []int{0, 1, 2}},
+	}
+
+	for name, test := range tests {
+		if _, err := raytracing.NewMesh(vertices, test.normals, test.texCoords, test.indices, nil); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	var mesh raytracing.Mesh
+	if mesh.Hit(raytracing.Ray{Direction: Vec{Z: -1}}, 0.001, 10000) != nil {
+		t.Error("expected zero mesh not to be hit")
+	}
+}
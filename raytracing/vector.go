@@ -68,3 +68,30 @@ func (a Vector) Cross(b Vector) Vector {
 		Z: a.X*b.Y - a.Y*b.X,
 	}
 }
+
+func (v Vector) Axis(axis int) float64 {
+	switch axis {
+	case 0:
+		return v.X
+	case 1:
+		return v.Y
+	default:
+		return v.Z
+	}
+}
+
+func (a Vector) Min(b Vector) Vector {
+	return Vector{
+		X: math.Min(a.X, b.X),
+		Y: math.Min(a.Y, b.Y),
+		Z: math.Min(a.Z, b.Z),
+	}
+}
+
+func (a Vector) Max(b Vector) Vector {
+	return Vector{
+		X: math.Max(a.X, b.X),
+		Y: math.Max(a.Y, b.Y),
+		Z: math.Max(a.Z, b.Z),
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/binary"
+	"fmt"
 	"image/color"
 	"io"
 	"log"
@@ -26,7 +27,8 @@ type Server struct {
 	*http.ServeMux
 
 	camera      raytracing.Camera
-	world       raytracing.Hittable
+	scene       raytracing.Scene
+	options     raytracing.RenderOptions
 	clientsLock sync.RWMutex
 	clients     map[ID]io.Writer // map client id -> response writer
 
@@ -36,13 +38,20 @@ type Server struct {
 	done func() <-chan struct{}
 }
 
-func NewServer(camera raytracing.Camera, world raytracing.Hittable) *Server {
+func NewServer(camera raytracing.Camera, scene raytracing.Scene) *Server {
 	s := &Server{
 		ServeMux: http.NewServeMux(),
 
 		clients: make(map[ID]io.Writer),
-		world:   world,
+		scene:   scene,
 		camera:  camera,
+		options: raytracing.RenderOptions{
+			ResolutionX: 400,
+			ResolutionY: 300,
+		},
+
+		// streams end with their client until ListenAndServe is called
+		done: func() <-chan struct{} { return nil },
 	}
 
 	s.HandleFunc("/stream", s.streamImage())
@@ -112,11 +121,7 @@ func (s *Server) streamImage() http.HandlerFunc {
 }
 
 func (s *Server) drawForAllListeners(ctx context.Context) {
-	options := raytracing.RenderOptions{
-		ResolutionX: 400,
-		ResolutionY: 300,
-	}
-	raytracing.Render(ctx, s.world, s.camera, options, func(x, y int, color color.RGBA) {
+	err := raytracing.Render(ctx, s.scene, s.camera, s.options, func(x, y int, color color.RGBA) {
 		// prevent concurrent write while iterating clients
 		s.clientsLock.RLock()
 		defer s.clientsLock.RUnlock()
@@ -134,12 +139,108 @@ func (s *Server) drawForAllListeners(ctx context.Context) {
 			binary.Write(w, binary.LittleEndian, uint8(color.B))
 		}
 	})
+	if err != nil && ctx.Err() == nil {
+		log.Printf("error rendering: %v", err)
+	}
+}
+
+// backgrounds selectable by name on the control endpoint, empty keeps the
+// background of the scene
+var backgrounds = map[string]raytracing.Background{
+	"":      nil,
+	"sky":   raytracing.SkyGradient(),
+	"black": raytracing.SolidBackground(raytracing.Black),
+	"white": raytracing.SolidBackground(raytracing.White),
+}
+
+// parseRenderOptions reads the render options from a request, missing
+// values keep their defaults
+func parseRenderOptions(r *http.Request, options raytracing.RenderOptions) (raytracing.RenderOptions, error) {
+	ints := map[string]*int{
+		"samples":    &options.SamplesPerPixel,
+		"bounces":    &options.MaxBounces,
+		"minsamples": &options.MinSamples,
+	}
+	for name, value := range ints {
+		if r.FormValue(name) == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(r.FormValue(name))
+		if err != nil {
+			return options, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*value = parsed
+	}
+
+	floats := map[string]*float64{
+		"gamma":    &options.Gamma,
+		"tmin":     &options.TMin,
+		"tmax":     &options.TMax,
+		"adaptive": &options.AdaptiveThreshold,
+	}
+	for name, value := range floats {
+		if r.FormValue(name) == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(r.FormValue(name), 64)
+		if err != nil {
+			return options, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*value = parsed
+	}
+
+	if r.FormValue("seed") != "" {
+		seed, err := strconv.ParseUint(r.FormValue("seed"), 10, 64)
+		if err != nil {
+			return options, fmt.Errorf("invalid seed: %w", err)
+		}
+		options.Seed = seed
+	}
+
+	if name := r.FormValue("sampler"); name != "" {
+		found := false
+		for t := raytracing.IndependentSampler; t <= raytracing.SobolSampler; t++ {
+			if t.String() == name {
+				options.Sampler, found = t, true
+			}
+		}
+		if !found {
+			return options, fmt.Errorf("unknown sampler %q", name)
+		}
+	}
+
+	background, ok := backgrounds[r.FormValue("background")]
+	if !ok {
+		return options, fmt.Errorf("unknown background %q", r.FormValue("background"))
+	}
+	options.Background = background
+
+	return options, options.Validate()
 }
 
 func (s *Server) changeValue() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
+		options, err := parseRenderOptions(r, raytracing.RenderOptions{
+			ResolutionX: 400,
+			ResolutionY: 300,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// depth of field is off unless an aperture is given
+		aperture := 0.0
+		if r.FormValue("aperture") != "" {
+			aperture, err = strconv.ParseFloat(r.FormValue("aperture"), 64)
+			if err != nil || aperture < 0 || math.IsNaN(aperture) || math.IsInf(aperture, 0) {
+				http.Error(w, fmt.Sprintf("invalid aperture %q", r.FormValue("aperture")), http.StatusBadRequest)
+				return
+			}
+		}
+
 		s.cancelCurrentLock.Lock()
 		if s.cancelCurrent != nil {
 			s.cancelCurrent()
@@ -153,7 +254,21 @@ func (s *Server) changeValue() http.HandlerFunc {
 		zoomPercent, _ := strconv.Atoi(r.FormValue("zoom"))
 		zoom := float64(zoomPercent)/200 + 0.2
 
-		s.camera = GenerateCamera(angleInRadians, zoom, 400, 300)
+		camera := GenerateCamera(angleInRadians, zoom, 400, 300)
+		if aperture > 0 {
+			camera.Aperture = aperture
+			camera.AutoFocus = true
+			camera.ApertureBlades = 6
+		}
+
+		// focus distance around the look at point, auto focus if missing
+		if focus, err := strconv.ParseFloat(r.FormValue("focus"), 64); err == nil {
+			camera.AutoFocus = false
+			camera.FocusDistance = focus
+		}
+
+		s.camera = camera
+		s.options = options
 
 		s.drawForAllListeners(ctx)
 	}
@@ -169,7 +284,7 @@ func (s *Server) randomizeColors() http.HandlerFunc {
 			return
 		}
 
-		s.world = GenerateWorld(randomColorPalette)
+		s.scene = GenerateWorld(randomColorPalette)
 		s.drawForAllListeners(ctx)
 	}
 }
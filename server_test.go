@@ -1,6 +1,8 @@
 package rtgo_test
 
 import (
+	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -18,8 +20,8 @@ func TestServer(t *testing.T) {
 		LookAt: Vec{},
 		Zoom:   1,
 	}
-	world := &raytracing.World{}
-	server := rtgo.NewServer(camera, world)
+	scene := raytracing.Scene{World: &raytracing.World{}}
+	server := rtgo.NewServer(camera, scene)
 
 	test := httptest.NewServer(server)
 
@@ -29,3 +31,82 @@ func TestServer(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestServerChangeValidatesOptions(t *testing.T) {
+	scene := raytracing.Scene{World: &raytracing.World{}}
+	server := rtgo.NewServer(rtgo.GenerateCamera(0, 1, 400, 300), scene)
+
+	test := httptest.NewServer(server)
+	defer test.Close()
+
+	tests := map[string]int{
+		"samples=4&bounces=3&gamma=2.2&background=black": http.StatusOK,
+		"samples=-1":        http.StatusBadRequest,
+		"bounces=many":      http.StatusBadRequest,
+		"tmin=2&tmax=1":     http.StatusBadRequest,
+		"background=purple": http.StatusBadRequest,
+		"seed=-3":           http.StatusBadRequest,
+		"sampler=sobol":     http.StatusOK,
+		"sampler=magic":     http.StatusBadRequest,
+		"adaptive=0.05":     http.StatusOK,
+		"adaptive=-1":       http.StatusBadRequest,
+		"aperture=0.5":      http.StatusOK,
+		"aperture=-1":       http.StatusBadRequest,
+		"aperture=wide":     http.StatusBadRequest,
+	}
+
+	for query, status := range tests {
+		res, err := http.Post(test.URL+"/change?"+query, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != status {
+			t.Errorf("%s: expected status %d, got %d", query, status, res.StatusCode)
+		}
+	}
+}
+
+func TestServerBackgroundOverrideDropsSun(t *testing.T) {
+	// a white ground lit only by the sun of the sky
+	sky := raytracing.NewSky(0.6, 2.4, 3)
+	scene := raytracing.Scene{
+		World:      raytracing.Sphere{Center: Vec{Y: -100.5}, Radius: 100, Material: raytracing.Lambertian(raytracing.White)},
+		Background: sky.Background,
+		Lights:     []raytracing.Light{sky.Sun()},
+	}
+	server := rtgo.NewServer(rtgo.GenerateCamera(0, 1, 400, 300), scene)
+
+	test := httptest.NewServer(server)
+	defer test.Close()
+
+	res, err := http.Post(test.URL+"/change?background=black&samples=1&bounces=2", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, res.StatusCode)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, test.URL+"/stream", nil)
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	// the last pixels may stay buffered, so only read most of the image
+	pixel := make([]byte, 7)
+	for i := 0; i < 400*300/2; i++ {
+		if _, err := io.ReadFull(res.Body, pixel); err != nil {
+			t.Fatal(err)
+		}
+		if pixel[4] != 0 || pixel[5] != 0 || pixel[6] != 0 {
+			t.Fatalf("expected black pixel without the sky and its sun, got %v", pixel[4:])
+		}
+	}
+}
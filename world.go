@@ -26,7 +26,7 @@ func GenerateCamera(angle float64, zoom float64, width, height int) raytracing.C
 	return camera
 }
 
-func GenerateWorld(colors []raytracing.Color) raytracing.Hittable {
+func GenerateWorld(colors []raytracing.Color) raytracing.Scene {
 	randomColor := func() raytracing.Color {
 		return colors[rand.Intn(len(colors)-1)]
 	}
@@ -65,5 +65,68 @@ func GenerateWorld(colors []raytracing.Color) raytracing.Hittable {
 		},
 	}
 
-	return world
+	// morning sun from the south-east, behind the default camera
+	sky := raytracing.NewSky(0.6, 2.4, 3)
+
+	return raytracing.Scene{
+		World:      world,
+		Background: sky.Background,
+		Lights:     []raytracing.Light{sky.Sun()},
+	}
+}
+
+// GenerateCornellBox builds the classic Cornell box, centered at the origin
+// and open towards +X so that it faces the default camera
+func GenerateCornellBox() raytracing.Scene {
+	red := raytracing.Lambertian(raytracing.Color{R: 0.65, G: 0.05, B: 0.05})
+	white := raytracing.Lambertian(raytracing.Color{R: 0.73, G: 0.73, B: 0.73})
+	green := raytracing.Lambertian(raytracing.Color{R: 0.12, G: 0.45, B: 0.15})
+
+	const size = 2.5
+
+	light := raytracing.NewQuadLight(
+		Vec{X: -0.6, Y: size - 0.01, Z: -0.5}, Vec{X: 1.2}, Vec{Z: 1},
+		raytracing.Color{R: 1, G: 1, B: 1}, 15,
+	)
+
+	world := raytracing.World{
+		Objects: []raytracing.Hittable{
+			// walls
+			raytracing.XYRect{X0: -size, X1: size, Y0: -size, Y1: size, K: -size, Material: red},
+			raytracing.XYRect{X0: -size, X1: size, Y0: -size, Y1: size, K: size, Material: green},
+			raytracing.YZRect{Y0: -size, Y1: size, Z0: -size, Z1: size, K: -size, Material: white},
+			raytracing.XZRect{X0: -size, X1: size, Z0: -size, Z1: size, K: -size, Material: white},
+			raytracing.XZRect{X0: -size, X1: size, Z0: -size, Z1: size, K: size, Material: white},
+
+			// ceiling light
+			light,
+
+			// tall and short block
+			mustTransform(
+				raytracing.NewBox(Vec{X: -0.75, Y: -size, Z: -0.75}, Vec{X: 0.75, Y: 0.5, Z: 0.75}, white),
+				raytracing.Translate(Vec{X: -1, Z: -0.8}).Multiply(raytracing.RotateY(15*math.Pi/180)),
+			),
+			mustTransform(
+				raytracing.NewBox(Vec{X: -0.75, Y: -size, Z: -0.75}, Vec{X: 0.75, Y: -1, Z: 0.75}, white),
+				raytracing.Translate(Vec{X: 0.8, Z: 0.9}).Multiply(raytracing.RotateY(-18*math.Pi/180)),
+			),
+		},
+	}
+
+	// the only light comes from the ceiling
+	return raytracing.Scene{
+		World:      world,
+		Background: raytracing.SolidBackground(raytracing.Black),
+		Lights:     []raytracing.Light{light},
+	}
+}
+
+// mustTransform places the objects of the built-in scenes, whose
+// transforms are always invertible
+func mustTransform(object raytracing.Hittable, transform raytracing.Matrix) raytracing.Hittable {
+	transformed, err := raytracing.NewTransformed(object, transform)
+	if err != nil {
+		panic(err)
+	}
+	return transformed
 }